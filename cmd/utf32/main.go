@@ -0,0 +1,161 @@
+// Command utf32 inspects strings at the code point level.
+//
+// Usage:
+//
+//	utf32 diff [-color] [-files] a b
+//
+// diff compares a and b code point by code point and prints, for each code
+// point, its utf-8 bytes, its UTF32 value, its general category, script and
+// Unicode name, prefixed with "=" when common to both inputs, "-" when only
+// in a and "+" when only in b. It reports inputs that differ only by
+// normalization, such as NFC and NFD forms of the same text. The exit status
+// is 0 if the inputs are identical, 1 if they differ and 2 on error.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/creack/utf32"
+)
+
+// ANSI escape sequences used by -color.
+const (
+	colorReset = "\x1b[0m"
+	colorRed   = "\x1b[31m"
+	colorGreen = "\x1b[32m"
+)
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdout, os.Stderr))
+}
+
+func run(args []string, stdout, stderr io.Writer) int {
+	if len(args) == 0 || args[0] != "diff" {
+		fmt.Fprintln(stderr, "usage: utf32 diff [-color] [-files] a b")
+		return 2
+	}
+	return runDiff(args[1:], stdout, stderr)
+}
+
+func runDiff(args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("diff", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	color := fs.Bool("color", false, "highlight differences with ANSI colors")
+	files := fs.Bool("files", false, "read a and b as file names instead of literal strings")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if fs.NArg() != 2 {
+		fmt.Fprintln(stderr, "usage: utf32 diff [-color] [-files] a b")
+		return 2
+	}
+	inputs := fs.Args()
+	if *files {
+		for i, name := range inputs {
+			buf, err := os.ReadFile(name)
+			if err != nil {
+				fmt.Fprintln(stderr, err)
+				return 2
+			}
+			inputs[i] = string(buf)
+		}
+	}
+
+	// Invalid bytes are kept as escapes so they show up in the dump.
+	a := utf32.ConvertUTF8toUTF32Escape(inputs[0])
+	b := utf32.ConvertUTF8toUTF32Escape(inputs[1])
+	diffs := utf32.DiffCodePoints(a, b)
+	for _, d := range diffs {
+		for _, ch := range d.Text {
+			printCodePoint(stdout, d.Op, ch, *color)
+		}
+	}
+
+	switch {
+	case len(diffs) <= 1 && (len(diffs) == 0 || diffs[0].Op == utf32.DiffEqual):
+		return 0
+	case utf32.CanonicallyEquivalent(a, b):
+		fmt.Fprintln(stdout, "inputs differ but are canonically equivalent (normalization only, e.g. NFC vs NFD)")
+	}
+	return 1
+}
+
+func printCodePoint(w io.Writer, op utf32.DiffOp, ch utf32.UTF32, color bool) {
+	mark, start, end := "=", "", ""
+	switch op {
+	case utf32.DiffDelete:
+		mark, start, end = "-", colorRed, colorReset
+	case utf32.DiffInsert:
+		mark, start, end = "+", colorGreen, colorReset
+	}
+	if !color {
+		start, end = "", ""
+	}
+
+	var hex []string
+	raw, err := utf32.ConvertUTF32toUTF8Escape([]utf32.UTF32{ch})
+	for i := 0; err == nil && i < len(raw); i++ {
+		hex = append(hex, fmt.Sprintf("%02x", raw[i]))
+	}
+	glyph := ""
+	if err == nil && unicode.IsGraphic(rune(ch)) {
+		glyph = raw
+		if unicode.Is(unicode.Mn, rune(ch)) {
+			// Show combining marks on a dotted circle.
+			glyph = "◌" + glyph
+		}
+	}
+	line := fmt.Sprintf("%s %-8s %-12s %-3s %-4s %-10s %s", mark, fmt.Sprintf("U+%04X", uint32(ch)), strings.Join(hex, " "), glyph, category(ch), script(ch), name(ch))
+	fmt.Fprintf(w, "%s%s%s\n", start, strings.TrimRight(line, " "), end)
+}
+
+// category returns the two-letter general category of ch.
+func category(ch utf32.UTF32) string {
+	switch {
+	case ch >= 0xdc80 && ch <= 0xdcff:
+		return "raw"
+	case ch > utf32.UniMaxLegalUTF32:
+		return "-"
+	}
+	for _, name := range categoryNames {
+		// LC groups Lu, Ll and Lt, which are more precise.
+		if name != "LC" && unicode.Is(unicode.Categories[name], rune(ch)) {
+			return name
+		}
+	}
+	return "Cn"
+}
+
+// script returns the script of ch, if any.
+func script(ch utf32.UTF32) string {
+	for _, name := range scriptNames {
+		if unicode.Is(unicode.Scripts[name], rune(ch)) {
+			return name
+		}
+	}
+	return ""
+}
+
+var (
+	categoryNames = sortedKeys(unicode.Categories, 2)
+	scriptNames   = sortedKeys(unicode.Scripts, 0)
+)
+
+// sortedKeys returns the names of tables, restricted to the given length if
+// not 0, in a stable order.
+func sortedKeys(tables map[string]*unicode.RangeTable, length int) []string {
+	var ret []string
+	for name := range tables {
+		if length == 0 || len(name) == length {
+			ret = append(ret, name)
+		}
+	}
+	sort.Strings(ret)
+	return ret
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDiff(t *testing.T) {
+	var tests = []struct {
+		args   []string
+		status int
+		expect []string
+	}{
+		{
+			args:   []string{"diff", "abc", "abc"},
+			status: 0,
+			expect: []string{"= U+0061   61           a   Ll   Latin      LATIN SMALL LETTER A"},
+		},
+		{
+			args:   []string{"diff", "café", "café"},
+			status: 1,
+			expect: []string{
+				"- U+00E9   c3 a9        é   Ll   Latin      LATIN SMALL LETTER E WITH ACUTE",
+				"+ U+0065   65           e   Ll   Latin      LATIN SMALL LETTER E",
+				"+ U+0301   cc 81        ◌́  Mn   Inherited  COMBINING ACUTE ACCENT",
+				"canonically equivalent",
+			},
+		},
+		{
+			args:   []string{"diff", "a\xffb", "ab"},
+			status: 1,
+			expect: []string{"- U+DCFF   ff               raw"},
+		},
+		{
+			args:   []string{"diff", "日", "한"},
+			status: 1,
+			expect: []string{
+				"- U+65E5   e6 97 a5     日   Lo   Han        CJK UNIFIED IDEOGRAPH-65E5",
+				"+ U+D55C   ed 95 9c     한   Lo   Hangul     HANGUL SYLLABLE HAN",
+			},
+		},
+		{
+			args:   []string{"diff", "a"},
+			status: 2,
+		},
+	}
+	for _, elem := range tests {
+		stdout, stderr := &strings.Builder{}, &strings.Builder{}
+		if expect, got := elem.status, run(elem.args, stdout, stderr); expect != got {
+			t.Fatalf("Unexpected status for %q.\nExpect:\t%d\nGot:\t%d\n%s", elem.args, expect, got, stderr)
+		}
+		for _, line := range elem.expect {
+			if !strings.Contains(stdout.String(), line) {
+				t.Fatalf("Missing line for %q.\nExpect:\t%s\nGot:\n%s\n", elem.args, line, stdout)
+			}
+		}
+	}
+}
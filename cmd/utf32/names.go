@@ -0,0 +1,101 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	_ "embed"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/creack/utf32"
+)
+
+// Character names from UnicodeData.txt, version 14.0, as gzipped
+// "XXXX;NAME" lines. Names derived from the code point, such as those of
+// ideographs and Hangul syllables, are left out and computed by name.
+//
+//go:embed names.txt.gz
+var namesData []byte
+
+// Ranges of code points named after a prefix and their hexadecimal value,
+// as in "CJK UNIFIED IDEOGRAPH-4E00".
+var hexNamed = []struct {
+	lo, hi utf32.UTF32
+	prefix string
+}{
+	{0x3400, 0x4DBF, "CJK UNIFIED IDEOGRAPH-"},
+	{0x4E00, 0x9FFF, "CJK UNIFIED IDEOGRAPH-"},
+	{0xF900, 0xFA6D, "CJK COMPATIBILITY IDEOGRAPH-"},
+	{0xFA70, 0xFAD9, "CJK COMPATIBILITY IDEOGRAPH-"},
+	{0x17000, 0x187F7, "TANGUT IDEOGRAPH-"},
+	{0x18B00, 0x18CD5, "KHITAN SMALL SCRIPT CHARACTER-"},
+	{0x18D00, 0x18D08, "TANGUT IDEOGRAPH-"},
+	{0x1B170, 0x1B2FB, "NUSHU CHARACTER-"},
+	{0x20000, 0x2A6DF, "CJK UNIFIED IDEOGRAPH-"},
+	{0x2A700, 0x2B738, "CJK UNIFIED IDEOGRAPH-"},
+	{0x2B740, 0x2B81D, "CJK UNIFIED IDEOGRAPH-"},
+	{0x2B820, 0x2CEA1, "CJK UNIFIED IDEOGRAPH-"},
+	{0x2CEB0, 0x2EBE0, "CJK UNIFIED IDEOGRAPH-"},
+	{0x2F800, 0x2FA1D, "CJK COMPATIBILITY IDEOGRAPH-"},
+	{0x30000, 0x3134A, "CJK UNIFIED IDEOGRAPH-"},
+}
+
+// Short names of the conjoining jamo making up Hangul syllable names.
+var (
+	hangulLeading  = strings.Fields("G GG N D DD R M B BB S SS _ J JJ C K T P H")
+	hangulVowel    = strings.Fields("A AE YA YAE EO E YEO YE O WA WAE OE YO U WEO WE WI YU EU YI I")
+	hangulTrailing = strings.Fields("_ G GG GS N NJ NH D L LG LM LB LS LT LP LH M B BS S SS NG J C K T P H")
+)
+
+const (
+	hangulSBase utf32.UTF32 = 0xac00
+	hangulSEnd  utf32.UTF32 = 0xd7a3
+)
+
+var (
+	namesOnce sync.Once
+	names     map[utf32.UTF32]string
+)
+
+// name returns the Unicode name of ch, or "" if it has none, such as
+// control characters, surrogates and unassigned code points.
+func name(ch utf32.UTF32) string {
+	for _, r := range hexNamed {
+		if ch >= r.lo && ch <= r.hi {
+			return fmt.Sprintf("%s%04X", r.prefix, uint32(ch))
+		}
+	}
+	if ch >= hangulSBase && ch <= hangulSEnd {
+		s := int(ch - hangulSBase)
+		t := hangulTrailing[s%len(hangulTrailing)]
+		v := hangulVowel[s/len(hangulTrailing)%len(hangulVowel)]
+		l := hangulLeading[s/len(hangulTrailing)/len(hangulVowel)]
+		return strings.ReplaceAll("HANGUL SYLLABLE "+l+v+t, "_", "")
+	}
+	namesOnce.Do(loadNames)
+	return names[ch]
+}
+
+// loadNames decodes namesData into names.
+func loadNames() {
+	names = map[utf32.UTF32]string{}
+	zr, err := gzip.NewReader(bytes.NewReader(namesData))
+	if err != nil {
+		panic(err)
+	}
+	scanner := bufio.NewScanner(zr)
+	for scanner.Scan() {
+		code, label, _ := strings.Cut(scanner.Text(), ";")
+		ch, err := strconv.ParseUint(code, 16, 32)
+		if err != nil {
+			panic(err)
+		}
+		names[utf32.UTF32(ch)] = label
+	}
+	if err := scanner.Err(); err != nil {
+		panic(err)
+	}
+}
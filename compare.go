@@ -0,0 +1,121 @@
+package utf32
+
+// Order is a binary ordering of []UTF32 sequences.
+type Order int
+
+// Order values.
+const (
+	// CodePointOrder compares code point values, as Go does for []rune.
+	CodePointOrder Order = iota
+	// UTF16Order compares utf-16 code units, as Java, JavaScript and C#
+	// compare strings. It differs from code point order in that the
+	// supplementary code points, encoded with surrogates, sort before
+	// U+E000 to U+FFFF.
+	UTF16Order
+	// UTF8Order compares utf-8 bytes, as Go does for strings. It is the same
+	// as code point order.
+	UTF8Order
+)
+
+// Compare compares a and b in code point order. The result is 0 if a == b,
+// -1 if a < b and +1 if a > b.
+func Compare(a, b []UTF32) int {
+	for i := 0; i < len(a) && i < len(b); i++ {
+		if a[i] != b[i] {
+			if a[i] < b[i] {
+				return -1
+			}
+			return 1
+		}
+	}
+	switch {
+	case len(a) < len(b):
+		return -1
+	case len(a) > len(b):
+		return 1
+	}
+	return 0
+}
+
+// CompareUTF16 compares a and b in utf-16 code unit order, so that Go sorts
+// the same way as Java or JavaScript do. Lone surrogates, which these
+// strings may hold, are compared as their own code unit.
+func CompareUTF16(a, b []UTF32) int {
+	var bufA, bufB [2]uint16
+	var ua, ub []uint16
+	for {
+		if len(ua) == 0 && len(a) > 0 {
+			ua, a = appendUTF16Units(bufA[:0], a[0]), a[1:]
+		}
+		if len(ub) == 0 && len(b) > 0 {
+			ub, b = appendUTF16Units(bufB[:0], b[0]), b[1:]
+		}
+		switch {
+		case len(ua) == 0 && len(ub) == 0:
+			return 0
+		case len(ua) == 0:
+			return -1
+		case len(ub) == 0:
+			return 1
+		case ua[0] < ub[0]:
+			return -1
+		case ua[0] > ub[0]:
+			return 1
+		}
+		ua, ub = ua[1:], ub[1:]
+	}
+}
+
+// CompareUTF8 compares a and b in utf-8 byte order, without encoding them.
+// utf-8 preserves code point order, so this is the same as Compare.
+func CompareUTF8(a, b []UTF32) int {
+	return Compare(a, b)
+}
+
+// CompareOrder compares a and b in the given order.
+func CompareOrder(a, b []UTF32, order Order) int {
+	if order == UTF16Order {
+		return CompareUTF16(a, b)
+	}
+	return Compare(a, b)
+}
+
+// appendUTF16Units appends the utf-16 code units of ch to dst. Lone
+// surrogates are kept as a single unit and values beyond U+10FFFF, which
+// utf-16 cannot encode, are replaced with U+FFFD.
+func appendUTF16Units(dst []uint16, ch UTF32) []uint16 {
+	switch {
+	case ch > UniMaxLegalUTF32:
+		return append(dst, uint16(replacementChar))
+	case ch > 0xffff:
+		ch -= 0x10000
+		return append(dst, uint16(UniSurHighStart+ch>>10), uint16(utf16LowStart+ch&0x3ff))
+	}
+	return append(dst, uint16(ch))
+}
+
+// SortKey returns a byte string such that comparing the keys of two
+// sequences with bytes.Compare gives the same result as comparing them in
+// the given order. Keys are 3 bytes per code point, or 2 bytes per utf-16
+// code unit in UTF16Order. Values beyond 0xFFFFFF, which are not code
+// points, all get the same weight.
+func SortKey(src []UTF32, order Order) []byte {
+	if order == UTF16Order {
+		ret := make([]byte, 0, 2*len(src))
+		var buf [2]uint16
+		for _, ch := range src {
+			for _, unit := range appendUTF16Units(buf[:0], ch) {
+				ret = append(ret, byte(unit>>8), byte(unit))
+			}
+		}
+		return ret
+	}
+	ret := make([]byte, 0, 3*len(src))
+	for _, ch := range src {
+		if ch > 0xffffff {
+			ch = 0xffffff
+		}
+		ret = append(ret, byte(ch>>16), byte(ch>>8), byte(ch))
+	}
+	return ret
+}
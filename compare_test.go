@@ -0,0 +1,151 @@
+package utf32
+
+import (
+	"bytes"
+	"math/rand"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+	"testing/quick"
+	"unicode/utf16"
+)
+
+// scalars is a []UTF32 of valid code points for testing/quick, biased
+// towards the ranges where the orders differ.
+type scalars []UTF32
+
+func (scalars) Generate(rnd *rand.Rand, size int) reflect.Value {
+	ret := make(scalars, rnd.Intn(size+1))
+	for i := range ret {
+		switch rnd.Intn(4) {
+		case 0:
+			ret[i] = UTF32(rnd.Intn(0x80))
+		case 1:
+			ret[i] = UTF32(0xe000 + rnd.Intn(0x2000))
+		case 2:
+			ret[i] = UTF32(0x10000 + rnd.Intn(0x100000))
+		default:
+			ret[i] = UTF32(rnd.Intn(0xd800))
+		}
+	}
+	return reflect.ValueOf(ret)
+}
+
+// surrogates is a []UTF32 of valid code points and lone surrogates, as
+// utf-16 strings may hold, for testing/quick.
+type surrogates []UTF32
+
+func (surrogates) Generate(rnd *rand.Rand, size int) reflect.Value {
+	ret := surrogates(scalars(nil).Generate(rnd, size).Interface().(scalars))
+	for i := range ret {
+		if rnd.Intn(3) == 0 {
+			ret[i] = UTF32(UniSurHighStart + UTF32(rnd.Intn(0x800)))
+		}
+	}
+	return reflect.ValueOf(ret)
+}
+
+// encodeUTF16 encodes src to utf-16, keeping lone surrogates as they are.
+func encodeUTF16(src []UTF32) []uint16 {
+	var ret []uint16
+	for _, ch := range src {
+		if ch >= UniSurHighStart && ch <= UniSurLowEnd {
+			ret = append(ret, uint16(ch))
+			continue
+		}
+		ret = append(ret, utf16.Encode([]rune{rune(ch)})...)
+	}
+	return ret
+}
+
+func runes(src []UTF32) []rune {
+	ret := make([]rune, len(src))
+	for i, ch := range src {
+		ret[i] = rune(ch)
+	}
+	return ret
+}
+
+func TestCompareProperties(t *testing.T) {
+	sign := func(n int) int {
+		switch {
+		case n < 0:
+			return -1
+		case n > 0:
+			return 1
+		}
+		return 0
+	}
+	// Short sequences often share prefixes, exercising the tie breaks.
+	cfg := &quick.Config{MaxCount: 5000, Values: func(args []reflect.Value, rnd *rand.Rand) {
+		for i := range args {
+			args[i] = scalars(nil).Generate(rnd, 3)
+		}
+	}}
+
+	codePoint := func(a, b scalars) bool {
+		expect := slices.Compare(runes(a), runes(b))
+		return Compare(a, b) == expect && sign(bytes.Compare(SortKey(a, CodePointOrder), SortKey(b, CodePointOrder))) == expect
+	}
+	utf8 := func(a, b scalars) bool {
+		expect := strings.Compare(string(runes(a)), string(runes(b)))
+		return CompareUTF8(a, b) == expect && sign(bytes.Compare(SortKey(a, UTF8Order), SortKey(b, UTF8Order))) == expect
+	}
+	utf16Order := func(a, b scalars) bool {
+		expect := slices.Compare(utf16.Encode(runes(a)), utf16.Encode(runes(b)))
+		return CompareUTF16(a, b) == expect && sign(bytes.Compare(SortKey(a, UTF16Order), SortKey(b, UTF16Order))) == expect
+	}
+	for name, fn := range map[string]any{"code point": codePoint, "utf-8": utf8, "utf-16": utf16Order} {
+		if err := quick.Check(fn, cfg); err != nil {
+			t.Fatalf("%s order: %s", name, err)
+		}
+	}
+
+	// Lone surrogates compare as their own code unit.
+	cfg.Values = func(args []reflect.Value, rnd *rand.Rand) {
+		for i := range args {
+			args[i] = surrogates(nil).Generate(rnd, 3)
+		}
+	}
+	lone := func(a, b surrogates) bool {
+		expect := slices.Compare(encodeUTF16(a), encodeUTF16(b))
+		return CompareUTF16(a, b) == expect && sign(bytes.Compare(SortKey(a, UTF16Order), SortKey(b, UTF16Order))) == expect
+	}
+	if err := quick.Check(lone, cfg); err != nil {
+		t.Fatalf("utf-16 order with lone surrogates: %s", err)
+	}
+}
+
+func TestCompareUTF16(t *testing.T) {
+	// U+FF21 sorts after U+1F600 in utf-16, before it in code point order.
+	a, b := []UTF32{0xff21}, []UTF32{0x1f600}
+	if expect, got := -1, Compare(a, b); expect != got {
+		t.Fatalf("Unexpected code point order.\nExpect:\t%d\nGot:\t%d\n", expect, got)
+	}
+	if expect, got := 1, CompareUTF16(a, b); expect != got {
+		t.Fatalf("Unexpected utf-16 order.\nExpect:\t%d\nGot:\t%d\n", expect, got)
+	}
+	if expect, got := 1, CompareOrder(a, b, UTF16Order); expect != got {
+		t.Fatalf("Unexpected utf-16 order.\nExpect:\t%d\nGot:\t%d\n", expect, got)
+	}
+
+	var tests = []struct {
+		a, b   []UTF32
+		expect int
+	}{
+		// A lone high surrogate is a prefix of the pairs it starts.
+		{a: []UTF32{0xd800}, b: []UTF32{0x10000}, expect: -1},
+		{a: []UTF32{0xd800, 0xe000}, b: []UTF32{0x10000}, expect: 1},
+		{a: []UTF32{0xdbff}, b: []UTF32{0x10000}, expect: 1},
+		{a: []UTF32{0xdc00}, b: []UTF32{0x10ffff}, expect: 1},
+		{a: []UTF32{0xdfff}, b: []UTF32{0xe000}, expect: -1},
+		// The same code units, paired or not.
+		{a: []UTF32{0xd800, 0xdc00}, b: []UTF32{0x10000}, expect: 0},
+	}
+	for _, elem := range tests {
+		if expect, got := elem.expect, CompareUTF16(elem.a, elem.b); expect != got {
+			t.Fatalf("Unexpected utf-16 order of %x and %x.\nExpect:\t%d\nGot:\t%d\n", elem.a, elem.b, expect, got)
+		}
+	}
+}
@@ -0,0 +1,89 @@
+package utf32
+
+import (
+	"context"
+	"fmt"
+)
+
+// Number of source units converted between two cancellation checks by the
+// context variants.
+const contextChunkSize = 64 << 10
+
+// CanceledError is returned by the context variants when their context is
+// done before the conversion completes.
+type CanceledError struct {
+	// Processed is the number of source units converted before the
+	// conversion stopped. The partial result returned along with the error
+	// holds their conversion.
+	Processed int
+	// Err is the error of the context.
+	Err error
+}
+
+func (e *CanceledError) Error() string {
+	return fmt.Sprintf("conversion stopped after %d units: %s", e.Processed, e.Err)
+}
+
+// Unwrap returns the error of the context.
+func (e *CanceledError) Unwrap() error {
+	return e.Err
+}
+
+// ConvertUTF8toUTF32Context is like ConvertUTF8toUTF32 but checks ctx for
+// cancellation periodically. If ctx is done, it returns what was converted
+// so far and a *CanceledError wrapping ctx.Err(). If progress is not nil,
+// it is called after each chunk with the number of bytes processed so far.
+func ConvertUTF8toUTF32Context(ctx context.Context, src string, progress func(processed int)) ([]UTF32, error) {
+	ret := make([]UTF32, 0, len(src))
+	for i := 0; i < len(src); {
+		if err := ctx.Err(); err != nil {
+			return ret, &CanceledError{Processed: i, Err: err}
+		}
+		// Cut the chunk on a sequence boundary.
+		end := i
+		for end < len(src) && end-i < contextChunkSize {
+			end += int(trailingBytesForUTF8[src[end]]) + 1
+		}
+		if end > len(src) {
+			end = len(src)
+		}
+		chunk, err := ConvertUTF8toUTF32(src[i:end])
+		if err != nil {
+			return nil, err
+		}
+		ret = append(ret, chunk...)
+		i = end
+		if progress != nil {
+			progress(i)
+		}
+	}
+	return ret, nil
+}
+
+// ConvertUTF32toUTF8Context is like ConvertUTF32toUTF8 but checks ctx for
+// cancellation periodically. If ctx is done, it returns what was converted
+// so far and a *CanceledError wrapping ctx.Err(). If progress is not nil,
+// it is called after each chunk with the number of source bytes processed
+// so far, 4 per code point, as for the utf-32 encoding of src.
+func ConvertUTF32toUTF8Context(ctx context.Context, src []UTF32, progress func(processed int)) (string, error) {
+	ret := make([]byte, 0, len(src))
+	for i := 0; i < len(src); {
+		if err := ctx.Err(); err != nil {
+			return string(ret), &CanceledError{Processed: i, Err: err}
+		}
+		end := i + contextChunkSize
+		if end > len(src) {
+			end = len(src)
+		}
+		chunk, err := ConvertUTF32toUTF8(src[i:end])
+		if err != nil {
+			return "", err
+		}
+		ret = append(ret, chunk...)
+		i = end
+		if progress != nil {
+			progress(4 * i)
+		}
+	}
+	return string(ret), nil
+}
@@ -0,0 +1,80 @@
+package utf32
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestConvertContext(t *testing.T) {
+	str := strings.Repeat("aé日𒔊", contextChunkSize/2)
+
+	var calls []int
+	utf32, err := ConvertUTF8toUTF32Context(context.Background(), str, func(processed int) { calls = append(calls, processed) })
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expect, got := 4*contextChunkSize/2, len(utf32); expect != got {
+		t.Fatalf("Unexpected length.\nExpect:\t%d\nGot:\t%d\n", expect, got)
+	}
+	if len(calls) < 2 || calls[len(calls)-1] != len(str) {
+		t.Fatalf("Unexpected progress calls: %v\n", calls)
+	}
+	// Chunks end on sequence boundaries.
+	for _, n := range calls {
+		if !utf8.ValidString(str[:n]) {
+			t.Fatalf("Unexpected progress in the middle of a sequence: %d\n", n)
+		}
+	}
+
+	calls = nil
+	back, err := ConvertUTF32toUTF8Context(context.Background(), utf32, func(processed int) { calls = append(calls, processed) })
+	if err != nil {
+		t.Fatal(err)
+	}
+	if back != str {
+		t.Fatal("Unexpected round trip result")
+	}
+	// Progress is in source bytes, 4 per code point.
+	for i, n := range calls {
+		expect := 4 * contextChunkSize * (i + 1)
+		if i == len(calls)-1 {
+			expect = 4 * len(utf32)
+		}
+		if expect != n {
+			t.Fatalf("Unexpected progress %d.\nExpect:\t%d\nGot:\t%d\n", i, expect, n)
+		}
+	}
+	if expect, got := (len(utf32)+contextChunkSize-1)/contextChunkSize, len(calls); expect != got {
+		t.Fatalf("Unexpected progress calls.\nExpect:\t%d\nGot:\t%d\n", expect, got)
+	}
+}
+
+func TestConvertContextCanceled(t *testing.T) {
+	str := strings.Repeat("aé日𒔊", contextChunkSize/2)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// Cancel once the first chunk is done.
+	utf32, err := ConvertUTF8toUTF32Context(ctx, str, func(int) { cancel() })
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("Unexpected error.\nExpect:\t%v\nGot:\t%v\n", context.Canceled, err)
+	}
+	var canceled *CanceledError
+	if !errors.As(err, &canceled) {
+		t.Fatalf("Unexpected error type: %T\n", err)
+	}
+	if canceled.Processed == 0 || canceled.Processed >= len(str) {
+		t.Fatalf("Unexpected processed count: %d\n", canceled.Processed)
+	}
+	if expect, got := mustConvert(t, str[:canceled.Processed]), utf32; !equal(expect, got) {
+		t.Fatalf("Unexpected partial result length.\nExpect:\t%d\nGot:\t%d\n", len(expect), len(got))
+	}
+
+	partial, err := ConvertUTF32toUTF8Context(ctx, utf32, nil)
+	if !errors.Is(err, context.Canceled) || partial != "" {
+		t.Fatalf("Unexpected result for a canceled context: %q, %v\n", partial, err)
+	}
+}
@@ -0,0 +1,500 @@
+package utf32
+
+import (
+	"encoding/binary"
+	"io"
+)
+
+// Special code points.
+const (
+	byteOrderMark   UTF32 = 0xfeff
+	replacementChar UTF32 = 0xfffd
+	utf16LowStart   UTF32 = 0xdc00
+)
+
+// NoncharacterPolicy tells a Converter what to do with the noncharacters
+// U+FDD0 to U+FDEF and U+nFFFE, U+nFFFF, which are valid but reserved for
+// internal use and should not be interchanged.
+type NoncharacterPolicy int
+
+// NoncharacterPolicy values.
+const (
+	// NoncharactersAllow passes noncharacters through.
+	NoncharactersAllow NoncharacterPolicy = iota
+	// NoncharactersReplace replaces noncharacters with the replacement
+	// character, U+FFFD unless set with WithReplacement.
+	NoncharactersReplace
+	// NoncharactersReject fails with ErrInvalidSource.
+	NoncharactersReject
+)
+
+// Converter converts between utf-8, []UTF32 and utf-32 or utf-16 encoded
+// bytes.
+// A Converter is immutable once created and safe for concurrent use.
+//
+// The zero value is not usable, use NewConverter.
+type Converter struct {
+	strict        bool
+	replace       bool
+	replacement   UTF32
+	escape        bool
+	noncharacters NoncharacterPolicy
+	order         binary.ByteOrder
+	bom           bool
+	utf16         bool
+}
+
+// Option configures a Converter.
+type Option func(*Converter)
+
+// Converters used by the package level functions.
+var (
+	defaultConverter = NewConverter()
+	escapeConverter  = NewConverter(WithSurrogateEscape())
+)
+
+// NewConverter returns a Converter configured with opts. Without options,
+// it behaves like ConvertUTF8toUTF32 and ConvertUTF32toUTF8, and encodes
+// utf-32 big endian without a byte order mark.
+func NewConverter(opts ...Option) *Converter {
+	c := &Converter{order: binary.BigEndian}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// WithStrict rejects malformed utf-8 that the default decoder lets through,
+// such as overlong forms or sequences with invalid continuation bytes.
+func WithStrict() Option {
+	return func(c *Converter) { c.strict = true }
+}
+
+// WithReplacement replaces invalid input with ch, usually U+FFFD, instead of
+// failing. Each malformed utf-8 sequence is replaced once. Implies
+// WithStrict. If ch is not a valid code point, such as a surrogate, U+FFFD
+// is used instead.
+func WithReplacement(ch UTF32) Option {
+	if ch > UniMaxLegalUTF32 || (ch >= UniSurHighStart && ch <= UniSurLowEnd) {
+		ch = replacementChar
+	}
+	return func(c *Converter) {
+		c.strict, c.replace, c.replacement = true, true, ch
+	}
+}
+
+// WithSurrogateEscape maps each undecodable utf-8 byte to a lone surrogate
+// when decoding and back when encoding, as ConvertUTF8toUTF32Escape and
+// ConvertUTF32toUTF8Escape do. Implies WithStrict.
+func WithSurrogateEscape() Option {
+	return func(c *Converter) { c.strict, c.escape = true, true }
+}
+
+// WithNoncharacters sets the policy for noncharacters. The default is
+// NoncharactersAllow.
+func WithNoncharacters(policy NoncharacterPolicy) Option {
+	return func(c *Converter) { c.noncharacters = policy }
+}
+
+// WithByteOrder sets the byte order of utf-32 bytes used by Reader and
+// Writer. The default is big endian.
+func WithByteOrder(order binary.ByteOrder) Option {
+	return func(c *Converter) { c.order = order }
+}
+
+// WithBOM makes Writer start its output with a byte order mark, and Reader
+// honor and strip the byte order mark at the start of its input.
+func WithBOM() Option {
+	return func(c *Converter) { c.bom = true }
+}
+
+// WithUTF16 makes Reader and Writer use utf-16 code units instead of
+// utf-32 ones, so the Converter can be used as a utf-16 codec.
+func WithUTF16() Option {
+	return func(c *Converter) { c.utf16 = true }
+}
+
+// isNoncharacter reports whether ch is one of the 66 noncharacters.
+func isNoncharacter(ch UTF32) bool {
+	return (ch >= 0xfdd0 && ch <= 0xfdef) || (ch&0xfffe == 0xfffe && ch <= UniMaxLegalUTF32)
+}
+
+// Decode converts the given utf-8 string to an utf-32 buffer.
+func (c *Converter) Decode(src string) ([]UTF32, error) {
+	return c.decode(make([]UTF32, 0, len(src)), src, nil)
+}
+
+// Append decodes the given utf-8 string and appends the result to dst.
+// On error, dst is returned unchanged.
+func (c *Converter) Append(dst []UTF32, src string) ([]UTF32, error) {
+	ret, err := c.decode(dst, src, nil)
+	if err != nil {
+		return dst, err
+	}
+	return ret, nil
+}
+
+// Encode converts the given utf-32 buffer to a utf-8 string.
+func (c *Converter) Encode(src []UTF32) (string, error) {
+	ret, err := c.encode(make([]byte, 0, len(src)*4), src, nil)
+	if err != nil {
+		return "", err
+	}
+	return string(ret), nil
+}
+
+// DecodeMap is like Decode but also returns the map between byte offsets in
+// src and indices in the result, which replaced sequences make differ in
+// length.
+func (c *Converter) DecodeMap(src string) ([]UTF32, *OffsetMap, error) {
+	m := &OffsetMap{}
+	ret, err := c.decode(make([]UTF32, 0, len(src)), src, m)
+	if err != nil {
+		return nil, nil, err
+	}
+	return ret, m, nil
+}
+
+// EncodeMap is like Encode but also returns the map between indices in src
+// and byte offsets in the result.
+func (c *Converter) EncodeMap(src []UTF32) (string, *OffsetMap, error) {
+	m := &OffsetMap{}
+	ret, err := c.encode(make([]byte, 0, len(src)*4), src, m)
+	if err != nil {
+		return "", nil, err
+	}
+	return string(ret), m, nil
+}
+
+// decode appends the conversion of src to dst, recording offsets in m if
+// not nil.
+func (c *Converter) decode(dst []UTF32, src string, m *OffsetMap) ([]UTF32, error) {
+	for i := 0; i < len(src); {
+		var (
+			ch   UTF32
+			size int
+			ok   bool
+		)
+		if c.strict {
+			ch, size, ok = decodeUTF8(src, i)
+		} else {
+			ch, size, ok = decodeUTF8Lax(src, i)
+		}
+		switch {
+		case ok:
+			var err error
+			if ch, err = c.checkNoncharacter(ch); err != nil {
+				return nil, err
+			}
+		case c.escape:
+			ch, size = escapeStart-0x80+UTF32(src[i]), 1
+		case c.replace:
+			ch, size = c.replacement, invalidUTF8Length(src, i)
+		default:
+			return nil, ErrInvalidSource
+		}
+		dst = append(dst, ch)
+		if m != nil {
+			m.add(size, 1)
+		}
+		i += size
+	}
+	return dst, nil
+}
+
+// encode appends the utf-8 encoding of src to dst, recording offsets in m
+// if not nil. On error, it returns dst with the encoding of the code points
+// preceding the invalid one.
+func (c *Converter) encode(dst []byte, src []UTF32, m *OffsetMap) ([]byte, error) {
+	for _, ch := range src {
+		if c.escape && ch >= escapeStart && ch <= escapeEnd {
+			dst = append(dst, byte(ch-escapeStart+0x80))
+			if m != nil {
+				m.add(1, 1)
+			}
+			continue
+		}
+		ch, err := c.check(ch)
+		if err != nil {
+			return dst, err
+		}
+
+		// Figure out how many bytes the result will require.
+		bytesToWrite, _ := lookupBytesToWrite(ch)
+		dst = appendUTF8(dst, ch, bytesToWrite)
+		if m != nil {
+			m.add(1, bytesToWrite)
+		}
+	}
+	return dst, nil
+}
+
+// check validates ch, returning its replacement if it is invalid and the
+// Converter replaces invalid input.
+func (c *Converter) check(ch UTF32) (UTF32, error) {
+	// UTF-16 surrogate values are illegal in UTF-32.
+	if ch > UniMaxLegalUTF32 || (ch >= UniSurHighStart && ch <= UniSurLowEnd) {
+		if !c.replace {
+			return 0, ErrInvalidSource
+		}
+		return c.replacement, nil
+	}
+	return c.checkNoncharacter(ch)
+}
+
+// checkNoncharacter applies the noncharacter policy to ch.
+func (c *Converter) checkNoncharacter(ch UTF32) (UTF32, error) {
+	if c.noncharacters == NoncharactersAllow || !isNoncharacter(ch) {
+		return ch, nil
+	}
+	if c.noncharacters == NoncharactersReject {
+		return 0, ErrInvalidSource
+	}
+	if c.replace {
+		return c.replacement, nil
+	}
+	return replacementChar, nil
+}
+
+// invalidUTF8Length returns the length of the maximal prefix of a utf-8
+// sequence at src[i]: the lead byte and the continuation bytes following it,
+// up to the length the lead byte announces.
+func invalidUTF8Length(src string, i int) int {
+	n := 1
+	extra := int(trailingBytesForUTF8[src[i]])
+	if src[i] < 0xc2 || extra > 3 {
+		return n
+	}
+	for n <= extra && i+n < len(src) && src[i+n]&0xc0 == byteMark {
+		n++
+	}
+	return n
+}
+
+// completeUTF8 returns the length of the longest prefix of src that does
+// not end with an incomplete utf-8 sequence.
+func (c *Converter) completeUTF8(src []byte) int {
+	if !c.strict {
+		// The lax decoder consumes the bytes announced by each lead byte
+		// whatever they are, so walk the sequences.
+		i := 0
+		for i < len(src) {
+			next := i + int(trailingBytesForUTF8[src[i]]) + 1
+			if next > len(src) {
+				return i
+			}
+			i = next
+		}
+		return i
+	}
+	for k := 1; k <= 3 && k <= len(src); k++ {
+		b := src[len(src)-k]
+		if b&0xc0 == byteMark {
+			continue
+		}
+		if b >= 0xc0 && int(trailingBytesForUTF8[b]) >= k {
+			return len(src) - k
+		}
+		break
+	}
+	return len(src)
+}
+
+// unitSize returns the size in bytes of the code units used by Reader
+// and Writer.
+func (c *Converter) unitSize() int {
+	if c.utf16 {
+		return 2
+	}
+	return 4
+}
+
+// Reader returns a reader decoding the utf-32 bytes read from r, in the
+// Converter's byte order, to utf-8. With WithUTF16, the bytes are utf-16.
+func (c *Converter) Reader(r io.Reader) io.Reader {
+	return &reader{c: c, r: r, order: c.order, started: !c.bom}
+}
+
+type reader struct {
+	c       *Converter
+	r       io.Reader
+	order   binary.ByteOrder
+	started bool
+
+	in    [4096]byte
+	inLen int
+	out   []byte
+	units []UTF32
+	err   error
+}
+
+func (rd *reader) Read(p []byte) (int, error) {
+	for len(rd.out) == 0 {
+		if rd.err != nil {
+			return 0, rd.err
+		}
+		n, err := rd.r.Read(rd.in[rd.inLen:])
+		rd.inLen += n
+		if !rd.started {
+			if rd.inLen < rd.c.unitSize() && err == nil {
+				continue
+			}
+			rd.started = true
+			rd.sniffBOM()
+		}
+		done := rd.parse(err == io.EOF)
+		rd.inLen = copy(rd.in[:], rd.in[done:rd.inLen])
+
+		out, encErr := rd.c.encode(rd.out[:0], rd.units, nil)
+		switch {
+		case encErr != nil:
+			rd.err = encErr
+		case err == io.EOF && rd.inLen > 0:
+			// Truncated unit.
+			if rd.c.replace {
+				out, _ = rd.c.encode(out, []UTF32{rd.c.replacement}, nil)
+				rd.inLen = 0
+				rd.err = err
+			} else {
+				rd.err = io.ErrUnexpectedEOF
+			}
+		case err != nil:
+			rd.err = err
+		}
+		rd.out = out
+	}
+	n := copy(p, rd.out)
+	rd.out = rd.out[n:]
+	return n, nil
+}
+
+// parse decodes the complete code units of the input into rd.units and
+// returns the number of bytes consumed. A utf-16 high surrogate ending the
+// input is kept for the next call, unless eof is set.
+func (rd *reader) parse(eof bool) int {
+	rd.units = rd.units[:0]
+	if !rd.c.utf16 {
+		i := 0
+		for ; i+4 <= rd.inLen; i += 4 {
+			rd.units = append(rd.units, UTF32(rd.order.Uint32(rd.in[i:])))
+		}
+		return i
+	}
+	i := 0
+	for ; i+2 <= rd.inLen; i += 2 {
+		ch := UTF32(rd.order.Uint16(rd.in[i:]))
+		if ch >= UniSurHighStart && ch < utf16LowStart {
+			if i+4 > rd.inLen && !eof {
+				break
+			}
+			if i+4 <= rd.inLen {
+				if lo := UTF32(rd.order.Uint16(rd.in[i+2:])); lo >= utf16LowStart && lo <= UniSurLowEnd {
+					ch = 0x10000 + (ch-UniSurHighStart)<<10 + lo - utf16LowStart
+					i += 2
+				}
+			}
+		}
+		// Lone surrogates are left for encode to reject.
+		rd.units = append(rd.units, ch)
+	}
+	return i
+}
+
+// sniffBOM strips a byte order mark from the input and adopts its order.
+func (rd *reader) sniffBOM() {
+	size := rd.c.unitSize()
+	if rd.inLen < size {
+		return
+	}
+	var be, le UTF32
+	if rd.c.utf16 {
+		be, le = UTF32(binary.BigEndian.Uint16(rd.in[:])), UTF32(binary.LittleEndian.Uint16(rd.in[:]))
+	} else {
+		be, le = UTF32(binary.BigEndian.Uint32(rd.in[:])), UTF32(binary.LittleEndian.Uint32(rd.in[:]))
+	}
+	switch byteOrderMark {
+	case be:
+		rd.order = binary.BigEndian
+	case le:
+		rd.order = binary.LittleEndian
+	default:
+		return
+	}
+	rd.inLen = copy(rd.in[:], rd.in[size:rd.inLen])
+}
+
+// Writer returns a writer encoding the utf-8 written to it as utf-32 bytes,
+// in the Converter's byte order, written to w. With WithUTF16, the bytes
+// are utf-16. A sequence split between two writes is held until the next
+// one. Close must be called to flush an incomplete sequence left at the
+// end; it does not close w.
+func (c *Converter) Writer(w io.Writer) io.WriteCloser {
+	return &writer{c: c, w: w, started: !c.bom}
+}
+
+type writer struct {
+	c       *Converter
+	w       io.Writer
+	started bool
+	pending []byte
+	units   []UTF32
+	buf     []byte
+}
+
+func (wr *writer) Write(p []byte) (int, error) {
+	data := append(wr.pending, p...)
+	n := wr.c.completeUTF8(data)
+	if err := wr.flush(data[:n]); err != nil {
+		return 0, err
+	}
+	wr.pending = append(wr.pending[:0], data[n:]...)
+	return len(p), nil
+}
+
+// Close flushes the incomplete sequence written last, if any.
+func (wr *writer) Close() error {
+	if len(wr.pending) == 0 {
+		return nil
+	}
+	err := wr.flush(wr.pending)
+	wr.pending = wr.pending[:0]
+	return err
+}
+
+// flush decodes data and writes the resulting code units.
+func (wr *writer) flush(data []byte) error {
+	units := wr.units[:0]
+	if !wr.started {
+		units = append(units, byteOrderMark)
+	}
+	units, err := wr.c.decode(units, string(data), nil)
+	if err != nil {
+		return err
+	}
+	wr.units = units
+	wr.buf = wr.buf[:0]
+	var tmp [4]byte
+	for _, ch := range units {
+		switch {
+		case !wr.c.utf16:
+			wr.c.order.PutUint32(tmp[:], uint32(ch))
+			wr.buf = append(wr.buf, tmp[:]...)
+		case ch >= 0x10000:
+			ch -= 0x10000
+			wr.c.order.PutUint16(tmp[:], uint16(UniSurHighStart+ch>>10))
+			wr.c.order.PutUint16(tmp[2:], uint16(utf16LowStart+ch&0x3ff))
+			wr.buf = append(wr.buf, tmp[:]...)
+		default:
+			wr.c.order.PutUint16(tmp[:], uint16(ch))
+			wr.buf = append(wr.buf, tmp[:2]...)
+		}
+	}
+	if len(wr.buf) == 0 {
+		return nil
+	}
+	if _, err := wr.w.Write(wr.buf); err != nil {
+		return err
+	}
+	wr.started = true
+	return nil
+}
@@ -0,0 +1,243 @@
+package utf32
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"testing"
+	"testing/iotest"
+)
+
+func TestConverterDecode(t *testing.T) {
+	var tests = []struct {
+		opts   []Option
+		str    string
+		expect []UTF32
+		err    error
+	}{
+		{opts: nil, str: "aé", expect: []UTF32{'a', 0xe9}},
+		{opts: nil, str: "\xc0\xaf", expect: []UTF32{'/'}},
+		{opts: []Option{WithStrict()}, str: "\xc0\xaf", err: ErrInvalidSource},
+		{opts: []Option{WithReplacement(0xfffd)}, str: "a\xe2\x82b\xff", expect: []UTF32{'a', 0xfffd, 'b', 0xfffd}},
+		{opts: []Option{WithReplacement(0xd800)}, str: "a\xffb", expect: []UTF32{'a', 0xfffd, 'b'}},
+		{opts: []Option{WithReplacement(0x110000)}, str: "a\xffb", expect: []UTF32{'a', 0xfffd, 'b'}},
+		{opts: []Option{WithSurrogateEscape()}, str: "a\xff", expect: []UTF32{'a', 0xdcff}},
+		{opts: nil, str: "￿", expect: []UTF32{0xffff}},
+		{opts: []Option{WithNoncharacters(NoncharactersReplace)}, str: "a﷐", expect: []UTF32{'a', 0xfffd}},
+		{opts: []Option{WithNoncharacters(NoncharactersReject)}, str: "\U0010ffff", err: ErrInvalidSource},
+	}
+	for _, elem := range tests {
+		got, err := NewConverter(elem.opts...).Decode(elem.str)
+		if err != elem.err {
+			t.Fatalf("Unexpected error for %q.\nExpect:\t%v\nGot:\t%v\n", elem.str, elem.err, err)
+		}
+		if err == nil && !equal(elem.expect, got) {
+			t.Fatalf("Unexpected result for %q.\nExpect:\t%U\nGot:\t%U\n", elem.str, elem.expect, got)
+		}
+	}
+
+	dst, err := NewConverter().Append([]UTF32{'x'}, "yz")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expect := []UTF32{'x', 'y', 'z'}; !equal(expect, dst) {
+		t.Fatalf("Unexpected result.\nExpect:\t%U\nGot:\t%U\n", expect, dst)
+	}
+}
+
+func TestConverterEncode(t *testing.T) {
+	var tests = []struct {
+		opts   []Option
+		src    []UTF32
+		expect string
+		err    error
+	}{
+		{opts: nil, src: []UTF32{'a', 0xe9}, expect: "aé"},
+		{opts: nil, src: []UTF32{0xd800}, err: ErrInvalidSource},
+		{opts: []Option{WithReplacement('?')}, src: []UTF32{'a', 0xd800, 0x110000}, expect: "a??"},
+		{opts: []Option{WithReplacement(0xdfff)}, src: []UTF32{'a', 0xd800}, expect: "a\ufffd"},
+		{opts: []Option{WithSurrogateEscape()}, src: []UTF32{'a', 0xdcff}, expect: "a\xff"},
+		{opts: []Option{WithNoncharacters(NoncharactersReject)}, src: []UTF32{0xfffe}, err: ErrInvalidSource},
+	}
+	for _, elem := range tests {
+		got, err := NewConverter(elem.opts...).Encode(elem.src)
+		if err != elem.err {
+			t.Fatalf("Unexpected error for %U.\nExpect:\t%v\nGot:\t%v\n", elem.src, elem.err, err)
+		}
+		if expect := elem.expect; expect != got {
+			t.Fatalf("Unexpected result for %U.\nExpect:\t%q\nGot:\t%q\n", elem.src, expect, got)
+		}
+	}
+}
+
+func TestConverterReaderWriter(t *testing.T) {
+	str := "héllo, 世界 𒔊"
+	for _, opts := range [][]Option{
+		nil,
+		{WithByteOrder(binary.LittleEndian)},
+		{WithByteOrder(binary.LittleEndian), WithBOM()},
+	} {
+		c := NewConverter(opts...)
+		buf := &bytes.Buffer{}
+		w := c.Writer(buf)
+		// Write one byte at a time to split the sequences.
+		for i := 0; i < len(str); i++ {
+			if _, err := w.Write([]byte{str[i]}); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if err := w.Close(); err != nil {
+			t.Fatal(err)
+		}
+		expectLen := 4 * len(mustConvert(t, str))
+		if c.bom {
+			expectLen += 4
+		}
+		if expect, got := expectLen, buf.Len(); expect != got {
+			t.Fatalf("Unexpected encoded length.\nExpect:\t%d\nGot:\t%d\n", expect, got)
+		}
+
+		got, err := io.ReadAll(c.Reader(bytes.NewReader(buf.Bytes())))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if expect := str; expect != string(got) {
+			t.Fatalf("Unexpected round trip.\nExpect:\t%q\nGot:\t%q\n", expect, got)
+		}
+	}
+
+	// A byte order mark overrides the configured order.
+	le := []byte{0xff, 0xfe, 0, 0, 'h', 0, 0, 0, 'i', 0, 0, 0}
+	got, err := io.ReadAll(NewConverter(WithBOM()).Reader(bytes.NewReader(le)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expect := "hi"; expect != string(got) {
+		t.Fatalf("Unexpected result.\nExpect:\t%q\nGot:\t%q\n", expect, got)
+	}
+
+	// Truncated units and invalid values are reported.
+	if _, err := io.ReadAll(NewConverter().Reader(bytes.NewReader([]byte{0, 0, 0, 'a', 0}))); err != io.ErrUnexpectedEOF {
+		t.Fatalf("Unexpected error.\nExpect:\t%v\nGot:\t%v\n", io.ErrUnexpectedEOF, err)
+	}
+	if _, err := io.ReadAll(NewConverter().Reader(bytes.NewReader([]byte{0, 0, 0xd8, 0}))); err != ErrInvalidSource {
+		t.Fatalf("Unexpected error.\nExpect:\t%v\nGot:\t%v\n", ErrInvalidSource, err)
+	}
+	w := NewConverter(WithStrict()).Writer(io.Discard)
+	if _, err := w.Write([]byte("a\xe2\x82")); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != ErrInvalidSource {
+		t.Fatalf("Unexpected error.\nExpect:\t%v\nGot:\t%v\n", ErrInvalidSource, err)
+	}
+}
+
+func TestConverterChunks(t *testing.T) {
+	// Truncated, invalid and noncharacter sequences among valid ones.
+	str := "a\xe2\x82bé\xff世\xef\xb7\x90𒔊\xf0\x92"
+	var tests = []struct {
+		opts []Option
+	}{
+		{opts: []Option{WithReplacement(0xfffd)}},
+		{opts: []Option{WithReplacement('?'), WithNoncharacters(NoncharactersReplace)}},
+		{opts: []Option{WithSurrogateEscape()}},
+		{opts: []Option{WithReplacement(0xfffd), WithUTF16(), WithBOM()}},
+	}
+	for _, elem := range tests {
+		c := NewConverter(elem.opts...)
+		whole := &bytes.Buffer{}
+		w := c.Writer(whole)
+		if _, err := w.Write([]byte(str)); err != nil {
+			t.Fatal(err)
+		}
+		if err := w.Close(); err != nil {
+			t.Fatal(err)
+		}
+		decoded, err := c.Decode(str)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// Split the input at every position.
+		for i := 0; i <= len(str); i++ {
+			buf := &bytes.Buffer{}
+			w := c.Writer(buf)
+			for _, chunk := range []string{str[:i], str[i:]} {
+				if _, err := w.Write([]byte(chunk)); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if err := w.Close(); err != nil {
+				t.Fatal(err)
+			}
+			if expect, got := whole.Bytes(), buf.Bytes(); !bytes.Equal(expect, got) {
+				t.Fatalf("Unexpected encoding of %q split at %d.\nExpect:\t% x\nGot:\t% x\n", str, i, expect, got)
+			}
+		}
+
+		// Read the encoding back one byte and half a buffer at a time.
+		expect, err := c.Encode(decoded)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, r := range []io.Reader{
+			iotest.OneByteReader(bytes.NewReader(whole.Bytes())),
+			iotest.HalfReader(bytes.NewReader(whole.Bytes())),
+			iotest.DataErrReader(bytes.NewReader(whole.Bytes())),
+		} {
+			got, err := io.ReadAll(c.Reader(r))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if expect != string(got) {
+				t.Fatalf("Unexpected decoding.\nExpect:\t%q\nGot:\t%q\n", expect, got)
+			}
+		}
+	}
+
+	// Invalid values split across reads are replaced.
+	var data []byte
+	for _, ch := range []UTF32{'a', 0xd800, 0xe9, 0x110000, 0x1250a} {
+		data = binary.BigEndian.AppendUint32(data, uint32(ch))
+	}
+	c := NewConverter(WithByteOrder(binary.BigEndian), WithReplacement('?'))
+	got, err := io.ReadAll(c.Reader(iotest.OneByteReader(bytes.NewReader(append(data, 0, 0)))))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expect := "a?é?𒔊?"; expect != string(got) {
+		t.Fatalf("Unexpected decoding.\nExpect:\t%q\nGot:\t%q\n", expect, got)
+	}
+}
+
+func TestConverterUTF16(t *testing.T) {
+	str := "héllo 𒔊"
+	// "h" and "é" as utf-16le, then the surrogate pair of U+1250A.
+	expect := []byte{0xff, 0xfe, 'h', 0, 0xe9, 0, 'l', 0, 'l', 0, 'o', 0, ' ', 0, 0x09, 0xd8, 0x0a, 0xdd}
+	c := NewConverter(WithUTF16(), WithByteOrder(binary.LittleEndian), WithBOM())
+	buf := &bytes.Buffer{}
+	w := c.Writer(buf)
+	if _, err := io.WriteString(w, str); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if got := buf.Bytes(); !bytes.Equal(expect, got) {
+		t.Fatalf("Unexpected result.\nExpect:\t%x\nGot:\t%x\n", expect, got)
+	}
+
+	// Split the input inside the surrogate pair.
+	got, err := io.ReadAll(c.Reader(io.MultiReader(bytes.NewReader(expect[:15]), bytes.NewReader(expect[15:]))))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if str != string(got) {
+		t.Fatalf("Unexpected round trip.\nExpect:\t%q\nGot:\t%q\n", str, got)
+	}
+
+	// Lone surrogates are invalid.
+	if _, err := io.ReadAll(NewConverter(WithUTF16()).Reader(bytes.NewReader([]byte{0xd8, 0x08, 0, 'a'}))); err != ErrInvalidSource {
+		t.Fatalf("Unexpected error.\nExpect:\t%v\nGot:\t%v\n", ErrInvalidSource, err)
+	}
+}
@@ -0,0 +1,315 @@
+package utf32
+
+// DiffOp is the kind of a Diff.
+type DiffOp int
+
+// DiffOp values.
+const (
+	DiffEqual DiffOp = iota
+	DiffDelete
+	DiffInsert
+)
+
+// Diff is one operation of an edit script turning a into b: Text is either
+// common to both, deleted from a or inserted from b.
+type Diff struct {
+	Op   DiffOp
+	Text []UTF32
+}
+
+// DiffCodePoints computes the shortest edit script turning a into b, one
+// code point at a time, using the linear space variant of Myers' O(ND)
+// algorithm. Consecutive operations of the same kind are merged, and their
+// Text are subslices of a or b.
+func DiffCodePoints(a, b []UTF32) []Diff {
+	md := &myersDiffer{a: a, b: b}
+	// The frontiers of the largest bisection are reused by all the others.
+	size := 2*((len(a)+len(b)+1)/2) + 2
+	md.vf, md.vb = make([]int, size), make([]int, size)
+	md.diff(0, len(a), 0, len(b))
+
+	ret := make([]Diff, 0, len(md.edits))
+	for _, e := range md.edits {
+		if e.op == DiffInsert {
+			ret = append(ret, Diff{Op: e.op, Text: b[e.start:e.end:e.end]})
+		} else {
+			ret = append(ret, Diff{Op: e.op, Text: a[e.start:e.end:e.end]})
+		}
+	}
+	return ret
+}
+
+// appendDiff appends an operation to ret, merging it with the last one if
+// they are of the same kind.
+func appendDiff(ret []Diff, op DiffOp, text []UTF32) []Diff {
+	if len(text) == 0 {
+		return ret
+	}
+	if n := len(ret); n > 0 && ret[n-1].Op == op {
+		ret[n-1].Text = append(ret[n-1].Text[:len(ret[n-1].Text):len(ret[n-1].Text)], text...)
+		return ret
+	}
+	return append(ret, Diff{Op: op, Text: text})
+}
+
+// myersEdit is an operation of an edit script over a span of a, for
+// DiffEqual and DiffDelete, or of b, for DiffInsert.
+type myersEdit struct {
+	op         DiffOp
+	start, end int
+}
+
+// myersDiffer computes the edit script of a and b by divide and conquer:
+// each step finds the middle snake of the shortest edit path, walking the
+// furthest reaching paths forward from the start and backward from the
+// end, and recurses on both sides of it. Only the current frontiers are
+// kept, so memory is O(N+M) instead of the O((N+M)D) of the saved
+// frontiers of the basic algorithm.
+type myersDiffer struct {
+	a, b   []UTF32
+	vf, vb []int
+	edits  []myersEdit
+}
+
+// add appends an operation, merging it with the last one if they are of
+// the same kind, in which case their spans are contiguous. The edits
+// between two equalities are kept as one deletion followed by one
+// insertion.
+func (md *myersDiffer) add(op DiffOp, start, end int) {
+	if start == end {
+		return
+	}
+	n := len(md.edits)
+	if op == DiffDelete && n > 0 && md.edits[n-1].op == DiffInsert {
+		if n > 1 && md.edits[n-2].op == DiffDelete {
+			md.edits[n-2].end = end
+			return
+		}
+		md.edits = append(md.edits, md.edits[n-1])
+		md.edits[n-1] = myersEdit{op: op, start: start, end: end}
+		return
+	}
+	if n > 0 && md.edits[n-1].op == op {
+		md.edits[n-1].end = end
+		return
+	}
+	md.edits = append(md.edits, myersEdit{op: op, start: start, end: end})
+}
+
+// diff appends the edit script of a[a0:a1] and b[b0:b1].
+func (md *myersDiffer) diff(a0, a1, b0, b1 int) {
+	// Strip the common prefix and suffix, which are frequent and cheap.
+	prefix := 0
+	for a0+prefix < a1 && b0+prefix < b1 && md.a[a0+prefix] == md.b[b0+prefix] {
+		prefix++
+	}
+	md.add(DiffEqual, a0, a0+prefix)
+	a0, b0 = a0+prefix, b0+prefix
+	suffix := 0
+	for a0 < a1-suffix && b0 < b1-suffix && md.a[a1-1-suffix] == md.b[b1-1-suffix] {
+		suffix++
+	}
+	a1, b1 = a1-suffix, b1-suffix
+
+	if a0 == a1 || b0 == b1 {
+		md.add(DiffDelete, a0, a1)
+		md.add(DiffInsert, b0, b1)
+	} else {
+		if x, y, ok := md.bisect(a0, a1, b0, b1); ok {
+			md.diff(a0, x, b0, y)
+			md.diff(x, a1, y, b1)
+		} else {
+			md.add(DiffDelete, a0, a1)
+			md.add(DiffInsert, b0, b1)
+		}
+	}
+	md.add(DiffEqual, a1, a1+suffix)
+}
+
+// bisect returns a point of the middle snake of the shortest edit path of
+// a[a0:a1] and b[b0:b1], which have neither a common prefix nor a common
+// suffix, splitting it into two non empty halves. It returns ok false if
+// the two have nothing in common.
+func (md *myersDiffer) bisect(a0, a1, b0, b1 int) (x, y int, ok bool) {
+	a, b := md.a[a0:a1], md.b[b0:b1]
+	n, m := len(a), len(b)
+	maxD := (n + m + 1) / 2
+	offset := maxD
+	vf, vb := md.vf[:2*maxD+2], md.vb[:2*maxD+2]
+	for i := range vf {
+		vf[i], vb[i] = -1, -1
+	}
+	vf[offset+1], vb[offset+1] = 0, 0
+	delta := n - m
+	// The forward and backward paths meet on a forward step if delta is
+	// odd, on a backward one if it is even.
+	front := delta%2 != 0
+	// Diagonals that went past the end of a or b are no longer walked.
+	kfStart, kfEnd, kbStart, kbEnd := 0, 0, 0, 0
+	for d := 0; d < maxD; d++ {
+		for k := -d + kfStart; k <= d-kfEnd; k += 2 {
+			var x int
+			if k == -d || (k != d && vf[offset+k-1] < vf[offset+k+1]) {
+				x = vf[offset+k+1]
+			} else {
+				x = vf[offset+k-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x, y = x+1, y+1
+			}
+			vf[offset+k] = x
+			switch {
+			case x > n:
+				kfEnd += 2
+			case y > m:
+				kfStart += 2
+			case front:
+				if kb := offset + delta - k; kb >= 0 && kb < len(vb) && vb[kb] != -1 && x >= n-vb[kb] {
+					return a0 + x, b0 + y, true
+				}
+			}
+		}
+		for k := -d + kbStart; k <= d-kbEnd; k += 2 {
+			var x int
+			if k == -d || (k != d && vb[offset+k-1] < vb[offset+k+1]) {
+				x = vb[offset+k+1]
+			} else {
+				x = vb[offset+k-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && a[n-1-x] == b[m-1-y] {
+				x, y = x+1, y+1
+			}
+			vb[offset+k] = x
+			switch {
+			case x > n:
+				kbEnd += 2
+			case y > m:
+				kbStart += 2
+			case !front:
+				if kf := offset + delta - k; kf >= 0 && kf < len(vf) && vf[kf] != -1 && vf[kf] >= n-x {
+					xf := vf[kf]
+					return a0 + xf, b0 + xf - (kf - offset), true
+				}
+			}
+		}
+	}
+	// The paths only fail to meet within maxD steps each if the shortest
+	// edit path has n+m steps.
+	return 0, 0, false
+}
+
+// DiffGraphemes is like DiffCodePoints but treats each extended grapheme
+// cluster as a unit, so an edit never splits a base character from its
+// combining marks or an emoji sequence.
+func DiffGraphemes(a, b []UTF32) []Diff {
+	// Number each distinct cluster, diff the numbers and map them back.
+	ids := map[string]UTF32{}
+	var clusters [][]UTF32
+	tokenize := func(src []UTF32) []UTF32 {
+		var ret []UTF32
+		for _, cluster := range Graphemes(src) {
+			key := string(clusterKey(cluster))
+			id, ok := ids[key]
+			if !ok {
+				id = UTF32(len(clusters))
+				ids[key] = id
+				clusters = append(clusters, cluster)
+			}
+			ret = append(ret, id)
+		}
+		return ret
+	}
+	ta, tb := tokenize(a), tokenize(b)
+
+	var ret []Diff
+	for _, d := range DiffCodePoints(ta, tb) {
+		var text []UTF32
+		for _, id := range d.Text {
+			text = append(text, clusters[id]...)
+		}
+		ret = appendDiff(ret, d.Op, text)
+	}
+	return ret
+}
+
+// clusterKey returns a byte representation of cluster usable as a map key.
+func clusterKey(cluster []UTF32) []byte {
+	ret := make([]byte, 0, 4*len(cluster))
+	for _, ch := range cluster {
+		ret = append(ret, byte(ch>>24), byte(ch>>16), byte(ch>>8), byte(ch))
+	}
+	return ret
+}
+
+// CleanupSemantic rewrites diffs to be easier for a human to read: short
+// equalities that are dwarfed by the edits on both sides of them are turned
+// into a deletion and an insertion, and the edits between two equalities
+// are regrouped as one deletion followed by one insertion. The result still
+// turns a into b.
+func CleanupSemantic(diffs []Diff) []Diff {
+	for {
+		changed := false
+		var ret []Diff
+		for i, d := range diffs {
+			if d.Op == DiffEqual && i > 0 && i < len(diffs)-1 {
+				before, after := editLength(diffs[:i], -1), editLength(diffs[i+1:], 1)
+				if len(d.Text) <= before && len(d.Text) <= after {
+					ret = append(ret, Diff{Op: DiffDelete, Text: d.Text}, Diff{Op: DiffInsert, Text: d.Text})
+					changed = true
+					continue
+				}
+			}
+			ret = append(ret, d)
+		}
+		diffs = regroupEdits(ret)
+		if !changed {
+			return diffs
+		}
+	}
+}
+
+// editLength returns the size of the larger of the deleted and inserted
+// text in the run of edits at the end (dir -1) or start (dir 1) of diffs.
+func editLength(diffs []Diff, dir int) int {
+	del, ins := 0, 0
+	i := 0
+	if dir < 0 {
+		i = len(diffs) - 1
+	}
+	for ; i >= 0 && i < len(diffs) && diffs[i].Op != DiffEqual; i += dir {
+		if diffs[i].Op == DiffDelete {
+			del += len(diffs[i].Text)
+		} else {
+			ins += len(diffs[i].Text)
+		}
+	}
+	if del > ins {
+		return del
+	}
+	return ins
+}
+
+// regroupEdits merges each run of edits into one deletion followed by one
+// insertion, and merges adjacent equalities.
+func regroupEdits(diffs []Diff) []Diff {
+	var ret []Diff
+	for i := 0; i < len(diffs); {
+		if diffs[i].Op == DiffEqual {
+			ret = appendDiff(ret, DiffEqual, diffs[i].Text)
+			i++
+			continue
+		}
+		var del, ins []UTF32
+		for ; i < len(diffs) && diffs[i].Op != DiffEqual; i++ {
+			if diffs[i].Op == DiffDelete {
+				del = append(del, diffs[i].Text...)
+			} else {
+				ins = append(ins, diffs[i].Text...)
+			}
+		}
+		ret = appendDiff(appendDiff(ret, DiffDelete, del), DiffInsert, ins)
+	}
+	return ret
+}
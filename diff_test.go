@@ -0,0 +1,197 @@
+package utf32
+
+import (
+	"math/rand"
+	"runtime"
+	"testing"
+)
+
+func TestDiffCodePoints(t *testing.T) {
+	var tests = []struct {
+		a, b   string
+		expect string
+	}{
+		{a: "", b: "", expect: ""},
+		{a: "abc", b: "abc", expect: "=abc"},
+		{a: "abc", b: "", expect: "-abc"},
+		{a: "", b: "日本", expect: "+日本"},
+		{a: "kitten", b: "sitting", expect: "-k+s=itt-e+i=n+g"},
+		{a: "café", b: "café", expect: "=caf-é+é"},
+		{a: "日本語", b: "日本人の語", expect: "=日本+人の=語"},
+	}
+	for _, elem := range tests {
+		got := formatDiffs(t, DiffCodePoints(mustConvert(t, elem.a), mustConvert(t, elem.b)))
+		if expect := elem.expect; expect != got {
+			t.Fatalf("Unexpected diff of %q and %q.\nExpect:\t%q\nGot:\t%q\n", elem.a, elem.b, expect, got)
+		}
+	}
+}
+
+func formatDiffs(t *testing.T, diffs []Diff) string {
+	t.Helper()
+	ret := ""
+	for _, d := range diffs {
+		ret += string("=-+"[d.Op]) + mustString(t, d.Text)
+	}
+	return ret
+}
+
+func TestDiffGraphemes(t *testing.T) {
+	var tests = []struct {
+		a, b   string
+		expect string
+	}{
+		{a: "café", b: "cafè", expect: "=caf-é+è"},
+		{a: "a\U0001F44D\U0001F3FDb", b: "a\U0001F44D\U0001F3FFb", expect: "=a-\U0001F44D\U0001F3FD+\U0001F44D\U0001F3FF=b"},
+	}
+	for _, elem := range tests {
+		got := formatDiffs(t, DiffGraphemes(mustConvert(t, elem.a), mustConvert(t, elem.b)))
+		if expect := elem.expect; expect != got {
+			t.Fatalf("Unexpected diff of %q and %q.\nExpect:\t%q\nGot:\t%q\n", elem.a, elem.b, expect, got)
+		}
+	}
+}
+
+func TestCleanupSemantic(t *testing.T) {
+	var tests = []struct {
+		a, b   string
+		expect string
+	}{
+		{a: "mouse", b: "sofas", expect: "-mouse+sofas"},
+		{a: "the cat", b: "the dog", expect: "=the -cat+dog"},
+		{a: "abcdef", b: "abXdef", expect: "=ab-c+X=def"},
+	}
+	for _, elem := range tests {
+		got := formatDiffs(t, CleanupSemantic(DiffCodePoints(mustConvert(t, elem.a), mustConvert(t, elem.b))))
+		if expect := elem.expect; expect != got {
+			t.Fatalf("Unexpected diff of %q and %q.\nExpect:\t%q\nGot:\t%q\n", elem.a, elem.b, expect, got)
+		}
+	}
+}
+
+// applyDiffs returns the texts a diff turns into each other.
+func applyDiffs(diffs []Diff) ([]UTF32, []UTF32) {
+	var a, b []UTF32
+	for _, d := range diffs {
+		if d.Op != DiffInsert {
+			a = append(a, d.Text...)
+		}
+		if d.Op != DiffDelete {
+			b = append(b, d.Text...)
+		}
+	}
+	return a, b
+}
+
+// editDistance returns the number of code points inserted or deleted by
+// the shortest edit script of a and b, from their longest common
+// subsequence.
+func editDistance(a, b []UTF32) int {
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			switch {
+			case a[i] == b[j]:
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			case lcs[i+1][j] > lcs[i][j+1]:
+				lcs[i][j] = lcs[i+1][j]
+			default:
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+	return len(a) + len(b) - 2*lcs[0][0]
+}
+
+// randomText returns n code points drawn from alphabet.
+func randomText(rnd *rand.Rand, n int, alphabet []UTF32) []UTF32 {
+	ret := make([]UTF32, n)
+	for i := range ret {
+		ret[i] = alphabet[rnd.Intn(len(alphabet))]
+	}
+	return ret
+}
+
+// randomEdits returns src with n random insertions, deletions and
+// replacements.
+func randomEdits(rnd *rand.Rand, src []UTF32, n int, alphabet []UTF32) []UTF32 {
+	ret := append([]UTF32(nil), src...)
+	for i := 0; i < n && len(ret) > 0; i++ {
+		pos := rnd.Intn(len(ret))
+		switch rnd.Intn(3) {
+		case 0:
+			ret = append(ret[:pos], append([]UTF32{alphabet[rnd.Intn(len(alphabet))]}, ret[pos:]...)...)
+		case 1:
+			ret = append(ret[:pos], ret[pos+1:]...)
+		default:
+			ret[pos] = alphabet[rnd.Intn(len(alphabet))]
+		}
+	}
+	return ret
+}
+
+func TestDiffCodePointsShortest(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+	alphabet := []UTF32{'a', 'b', 'c', 0xe9, 0x65e5}
+	for i := 0; i < 500; i++ {
+		a := randomText(rnd, rnd.Intn(30), alphabet[:1+rnd.Intn(len(alphabet))])
+		b := randomEdits(rnd, a, rnd.Intn(10), alphabet)
+		diffs := DiffCodePoints(a, b)
+		gotA, gotB := applyDiffs(diffs)
+		if !equal(a, gotA) || !equal(b, gotB) {
+			t.Fatalf("Diff of %x and %x does not turn one into the other: %v", a, b, diffs)
+		}
+		edits := 0
+		for _, d := range diffs {
+			if d.Op != DiffEqual {
+				edits += len(d.Text)
+			}
+		}
+		if expect, got := editDistance(a, b), edits; expect != got {
+			t.Fatalf("Unexpected edit length for %x and %x.\nExpect:\t%d\nGot:\t%d\n", a, b, expect, got)
+		}
+	}
+}
+
+func TestDiffLarge(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+	alphabet := []UTF32{'a', 'b', 'c', ' ', 0xe9, 0x65e5, 0x672c, 0x1f600}
+	long := randomText(rnd, 50000, alphabet)
+	var tests = []struct {
+		name string
+		a, b []UTF32
+	}{
+		{name: "scattered edits", a: long, b: randomEdits(rnd, long, 1000, alphabet)},
+		{name: "unrelated", a: randomText(rnd, 10000, alphabet), b: randomText(rnd, 10000, alphabet)},
+	}
+	for _, elem := range tests {
+		// Memory must grow with the size of the inputs, not with their
+		// product with the edit distance.
+		var before, after runtime.MemStats
+		runtime.ReadMemStats(&before)
+		diffs := DiffCodePoints(elem.a, elem.b)
+		runtime.ReadMemStats(&after)
+		if limit, got := uint64(32<<20), after.TotalAlloc-before.TotalAlloc; got > limit {
+			t.Fatalf("Unexpected allocations for %s.\nExpect:\t<= %d\nGot:\t%d\n", elem.name, limit, got)
+		}
+		if a, b := applyDiffs(diffs); !equal(elem.a, a) || !equal(elem.b, b) {
+			t.Fatalf("Diff of %s does not turn one into the other", elem.name)
+		}
+
+		// Grapheme diffs, cleanup and patches share the implementation.
+		diffs = CleanupSemantic(DiffGraphemes(elem.a, elem.b))
+		if a, b := applyDiffs(diffs); !equal(elem.a, a) || !equal(elem.b, b) {
+			t.Fatalf("Grapheme diff of %s does not turn one into the other", elem.name)
+		}
+		patched, err := ApplyPatch(elem.a, MakePatch(diffs, 4))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !equal(elem.b, patched) {
+			t.Fatalf("Patch of %s does not turn one into the other", elem.name)
+		}
+	}
+}
@@ -0,0 +1,36 @@
+package utf32
+
+// Range of the lone low surrogates used to escape undecodable bytes, as
+// defined by PEP 383.
+const (
+	escapeStart UTF32 = 0xdc80
+	escapeEnd   UTF32 = 0xdcff
+)
+
+// ConvertUTF8toUTF32Escape converts the given utf-8 string to an utf-32
+// buffer, mapping each byte that is not part of a valid utf-8 sequence to
+// the lone surrogate U+DC80 to U+DCFF, following Python's "surrogateescape"
+// error handler (PEP 383). It never fails, and ConvertUTF32toUTF8Escape
+// restores the original bytes exactly.
+//
+// The result may hold surrogates, so it is not valid utf-32 and is rejected
+// by ConvertUTF32toUTF8.
+func ConvertUTF8toUTF32Escape(src string) []UTF32 {
+	return convertUTF8toUTF32Escape(src, nil)
+}
+
+// convertUTF8toUTF32Escape implements ConvertUTF8toUTF32Escape, recording
+// offsets in m if not nil.
+func convertUTF8toUTF32Escape(src string, m *OffsetMap) []UTF32 {
+	// Escaping never fails.
+	ret, _ := escapeConverter.decode(make([]UTF32, 0, len(src)), src, m)
+	return ret
+}
+
+// ConvertUTF32toUTF8Escape converts the given utf-32 buffer to a utf-8
+// string, turning the lone surrogates U+DC80 to U+DCFF produced by
+// ConvertUTF8toUTF32Escape back into the raw bytes 0x80 to 0xFF. Other
+// surrogates and out of range values are rejected with ErrInvalidSource.
+func ConvertUTF32toUTF8Escape(src []UTF32) (string, error) {
+	return escapeConverter.Encode(src)
+}
@@ -0,0 +1,44 @@
+package utf32
+
+import "testing"
+
+func TestEscapeRoundTrip(t *testing.T) {
+	var tests = []struct {
+		str    string
+		expect []UTF32
+	}{
+		{str: "héllo", expect: []UTF32{'h', 'é', 'l', 'l', 'o'}},
+		{str: "a\xffb", expect: []UTF32{'a', 0xdcff, 'b'}},
+		{str: "\xc3", expect: []UTF32{0xdcc3}},
+		{str: "\xc0\xaf", expect: []UTF32{0xdcc0, 0xdcaf}},
+		{str: "\xed\xb2\x80", expect: []UTF32{0xdced, 0xdcb2, 0xdc80}},
+		{str: "\xf4\x90\x80\x80", expect: []UTF32{0xdcf4, 0xdc90, 0xdc80, 0xdc80}},
+		{str: "\xe2\x82x𒔊", expect: []UTF32{0xdce2, 0xdc82, 'x', 0x1250a}},
+	}
+	for _, elem := range tests {
+		utf32 := ConvertUTF8toUTF32Escape(elem.str)
+		if expect, got := elem.expect, utf32; len(expect) != len(got) {
+			t.Fatalf("Unexpected result for %q.\nExpect:\t%U\nGot:\t%U\n", elem.str, expect, got)
+		}
+		for i := range utf32 {
+			if expect, got := elem.expect[i], utf32[i]; expect != got {
+				t.Fatalf("Unexpected result for %q.\nExpect:\t%U\nGot:\t%U\n", elem.str, elem.expect, utf32)
+			}
+		}
+		str, err := ConvertUTF32toUTF8Escape(utf32)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if expect, got := elem.str, str; expect != got {
+			t.Fatalf("Unexpected round trip.\nExpect:\t%q\nGot:\t%q\n", expect, got)
+		}
+	}
+}
+
+func TestEscapeInvalid(t *testing.T) {
+	for _, src := range [][]UTF32{{0xd800}, {'a', 0xdc7f}, {0x110000}} {
+		if _, err := ConvertUTF32toUTF8Escape(src); err != ErrInvalidSource {
+			t.Fatalf("Unexpected error for %U.\nExpect:\t%v\nGot:\t%v\n", src, ErrInvalidSource, err)
+		}
+	}
+}
@@ -0,0 +1,203 @@
+package utf32
+
+import (
+	"math/bits"
+	"sort"
+)
+
+// fmSampleRate is the distance between the text positions whose suffix
+// array entry is kept by FMIndex for Locate.
+const fmSampleRate = 32
+
+// FMIndex is a compressed full-text index of a []UTF32 text. It counts and
+// locates the occurrences of a pattern in time proportional to the length
+// of the pattern, not of the text, and positions are in code points, so
+// matches never start or end inside a character.
+//
+// The Burrows-Wheeler transform of the text is stored in a wavelet matrix,
+// using about log2 of the alphabet size bits per code point, plus one
+// suffix array sample every 32 code points.
+type FMIndex struct {
+	alphabet []UTF32
+	counts   []int // Number of symbols smaller than each symbol.
+	bwt      waveletMatrix
+	sampled  bitVector
+	samples  []int
+}
+
+// NewFMIndex returns the FM-index of text.
+func NewFMIndex(text []UTF32) *FMIndex {
+	s, alphabet := denseAlphabet(text)
+	sa := sais(s, len(alphabet)+1)
+
+	idx := &FMIndex{alphabet: alphabet, counts: make([]int, len(alphabet)+2)}
+	bwt := make([]int, len(sa))
+	sampled := make([]bool, len(sa))
+	for i, pos := range sa {
+		if pos > 0 {
+			bwt[i] = s[pos-1]
+		} else {
+			bwt[i] = s[len(s)-1]
+		}
+		if pos%fmSampleRate == 0 {
+			sampled[i] = true
+			idx.samples = append(idx.samples, pos)
+		}
+		idx.counts[s[i]+1]++
+	}
+	for c := 1; c < len(idx.counts); c++ {
+		idx.counts[c] += idx.counts[c-1]
+	}
+	idx.bwt = newWaveletMatrix(bwt, len(alphabet)+1)
+	idx.sampled = newBitVector(sampled)
+	return idx
+}
+
+// Len returns the length of the indexed text.
+func (idx *FMIndex) Len() int { return idx.bwt.n - 1 }
+
+// symbol returns the dense symbol of ch.
+func (idx *FMIndex) symbol(ch UTF32) (int, bool) {
+	i := sort.Search(len(idx.alphabet), func(i int) bool { return idx.alphabet[i] >= ch })
+	return i + 1, i < len(idx.alphabet) && idx.alphabet[i] == ch
+}
+
+// rows returns the range of suffix array rows of the suffixes starting
+// with pattern.
+func (idx *FMIndex) rows(pattern []UTF32) (int, int) {
+	lo, hi := 0, idx.bwt.n
+	for i := len(pattern) - 1; i >= 0 && lo < hi; i-- {
+		c, ok := idx.symbol(pattern[i])
+		if !ok {
+			return 0, 0
+		}
+		lo = idx.counts[c] + idx.bwt.rank(c, lo)
+		hi = idx.counts[c] + idx.bwt.rank(c, hi)
+	}
+	return lo, hi
+}
+
+// Count returns the number of occurrences of pattern in the text. The empty
+// pattern occurs at every position, including the end.
+func (idx *FMIndex) Count(pattern []UTF32) int {
+	lo, hi := idx.rows(pattern)
+	return hi - lo
+}
+
+// Locate returns the sorted code point positions of the occurrences of
+// pattern in the text.
+func (idx *FMIndex) Locate(pattern []UTF32) []int {
+	lo, hi := idx.rows(pattern)
+	ret := make([]int, 0, hi-lo)
+	for row := lo; row < hi; row++ {
+		// Walk the text backward until a sampled position.
+		r, steps := row, 0
+		for !idx.sampled.get(r) {
+			c := idx.bwt.access(r)
+			r = idx.counts[c] + idx.bwt.rank(c, r)
+			steps++
+		}
+		ret = append(ret, idx.samples[idx.sampled.rank1(r)]+steps)
+	}
+	sort.Ints(ret)
+	return ret
+}
+
+// bitVector is a bit array with constant time rank.
+type bitVector struct {
+	words []uint64
+	ranks []int // Number of ones before each word.
+}
+
+func newBitVector(b []bool) bitVector {
+	bv := bitVector{words: make([]uint64, len(b)/64+1)}
+	for i, set := range b {
+		if set {
+			bv.words[i/64] |= 1 << (i % 64)
+		}
+	}
+	bv.ranks = make([]int, len(bv.words))
+	for i := 1; i < len(bv.words); i++ {
+		bv.ranks[i] = bv.ranks[i-1] + bits.OnesCount64(bv.words[i-1])
+	}
+	return bv
+}
+
+func (bv bitVector) get(i int) bool {
+	return bv.words[i/64]&(1<<(i%64)) != 0
+}
+
+// rank1 returns the number of ones before i.
+func (bv bitVector) rank1(i int) int {
+	return bv.ranks[i/64] + bits.OnesCount64(bv.words[i/64]&(1<<(i%64)-1))
+}
+
+// waveletMatrix stores a sequence of symbols one bit plane at a time, most
+// significant first, each plane stably sorted by the bits above it, giving
+// rank and access in time proportional to the number of planes.
+type waveletMatrix struct {
+	n      int
+	levels []bitVector
+	zeros  []int // Number of zeros in each level.
+}
+
+func newWaveletMatrix(s []int, k int) waveletMatrix {
+	wm := waveletMatrix{n: len(s)}
+	depth := bits.Len(uint(k - 1))
+	cur := append([]int(nil), s...)
+	next := make([]int, len(s))
+	plane := make([]bool, len(s))
+	for l := depth - 1; l >= 0; l-- {
+		zeros := 0
+		for i, c := range cur {
+			plane[i] = c>>l&1 != 0
+			if !plane[i] {
+				zeros++
+			}
+		}
+		z, o := 0, zeros
+		for i, c := range cur {
+			if plane[i] {
+				next[o] = c
+				o++
+			} else {
+				next[z] = c
+				z++
+			}
+		}
+		wm.levels = append(wm.levels, newBitVector(plane))
+		wm.zeros = append(wm.zeros, zeros)
+		cur, next = next, cur
+	}
+	return wm
+}
+
+// access returns the i-th symbol.
+func (wm waveletMatrix) access(i int) int {
+	c := 0
+	for l, bv := range wm.levels {
+		if bv.get(i) {
+			c = c<<1 | 1
+			i = wm.zeros[l] + bv.rank1(i)
+		} else {
+			c <<= 1
+			i -= bv.rank1(i)
+		}
+	}
+	return c
+}
+
+// rank returns the number of occurrences of c before i.
+func (wm waveletMatrix) rank(c, i int) int {
+	start := 0
+	for l, bv := range wm.levels {
+		if c>>(len(wm.levels)-1-l)&1 != 0 {
+			start = wm.zeros[l] + bv.rank1(start)
+			i = wm.zeros[l] + bv.rank1(i)
+		} else {
+			start -= bv.rank1(start)
+			i -= bv.rank1(i)
+		}
+	}
+	return i - start
+}
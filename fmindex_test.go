@@ -0,0 +1,70 @@
+package utf32
+
+import (
+	"math/rand"
+	"reflect"
+	"testing"
+)
+
+// naiveLocate returns the positions of pattern in text.
+func naiveLocate(text, pattern []UTF32) []int {
+	ret := []int{}
+	for i := 0; i+len(pattern) <= len(text); i++ {
+		if equal(text[i:i+len(pattern)], pattern) {
+			ret = append(ret, i)
+		}
+	}
+	return ret
+}
+
+func TestFMIndex(t *testing.T) {
+	text := mustConvert(t, "東京都の東京タワーと東京駅、Tokyo 東京")
+	idx := NewFMIndex(text)
+	if expect, got := len(text), idx.Len(); expect != got {
+		t.Fatalf("Unexpected length.\nExpect:\t%d\nGot:\t%d\n", expect, got)
+	}
+	var tests = []struct {
+		pattern string
+		expect  []int
+	}{
+		{pattern: "東京", expect: []int{0, 4, 10, 20}},
+		{pattern: "東京都", expect: []int{0}},
+		{pattern: "京", expect: []int{1, 5, 11, 21}},
+		{pattern: "Tokyo", expect: []int{14}},
+		{pattern: "大阪", expect: []int{}},
+		{pattern: "東京東", expect: []int{}},
+	}
+	for _, elem := range tests {
+		pattern := mustConvert(t, elem.pattern)
+		if expect, got := len(elem.expect), idx.Count(pattern); expect != got {
+			t.Fatalf("Unexpected count for %q.\nExpect:\t%d\nGot:\t%d\n", elem.pattern, expect, got)
+		}
+		if expect, got := elem.expect, idx.Locate(pattern); !reflect.DeepEqual(expect, got) {
+			t.Fatalf("Unexpected positions for %q.\nExpect:\t%v\nGot:\t%v\n", elem.pattern, expect, got)
+		}
+	}
+	if expect, got := len(text)+1, idx.Count(nil); expect != got {
+		t.Fatalf("Unexpected count for the empty pattern.\nExpect:\t%d\nGot:\t%d\n", expect, got)
+	}
+}
+
+func TestFMIndexRandom(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+	alphabet := []UTF32{'a', 'b', 'c', 0xe9, 0x65e5, 0x1f600}
+	for i := 0; i < 100; i++ {
+		text := make([]UTF32, rnd.Intn(300))
+		for j := range text {
+			text[j] = alphabet[rnd.Intn(3)]
+		}
+		idx := NewFMIndex(text)
+		for j := 0; j < 20; j++ {
+			pattern := make([]UTF32, 1+rnd.Intn(4))
+			for k := range pattern {
+				pattern[k] = alphabet[rnd.Intn(len(alphabet))]
+			}
+			if expect, got := naiveLocate(text, pattern), idx.Locate(pattern); !reflect.DeepEqual(expect, got) {
+				t.Fatalf("Unexpected positions of %v in %v.\nExpect:\t%v\nGot:\t%v\n", pattern, text, expect, got)
+			}
+		}
+	}
+}
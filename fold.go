@@ -0,0 +1,63 @@
+package utf32
+
+import "unicode"
+
+// Folding selects the differences ignored when comparing keys, as in Trie.
+// Folding maps each code point independently, so positions in the folded
+// text can be traced back to the original.
+type Folding uint8
+
+// Folding flags.
+const (
+	// FoldCase maps each code point to the smallest member of its simple
+	// case folding orbit, so "K", "k" and the Kelvin sign "K" match.
+	FoldCase Folding = 1 << iota
+	// FoldDiacritics decomposes code points and drops their nonspacing
+	// marks, so "é" matches "e".
+	FoldDiacritics
+)
+
+// Apply returns src folded according to f.
+func (f Folding) Apply(src []UTF32) []UTF32 {
+	ret := make([]UTF32, 0, len(src))
+	for _, ch := range src {
+		ret = f.appendFolded(ret, ch)
+	}
+	return ret
+}
+
+// appendFolded appends the folding of ch to dst.
+func (f Folding) appendFolded(dst []UTF32, ch UTF32) []UTF32 {
+	if f&FoldDiacritics == 0 {
+		if f&FoldCase != 0 {
+			ch = foldCase(ch)
+		}
+		return append(dst, ch)
+	}
+	start := len(dst)
+	dst = appendDecomposed(dst, ch)
+	out := start
+	for _, ch := range dst[start:] {
+		if unicode.Is(unicode.Mn, rune(ch)) {
+			continue
+		}
+		if f&FoldCase != 0 {
+			ch = foldCase(ch)
+		}
+		dst[out] = ch
+		out++
+	}
+	return dst[:out]
+}
+
+// foldCase returns the smallest code point of the simple case folding
+// orbit of ch.
+func foldCase(ch UTF32) UTF32 {
+	lo := ch
+	for r := unicode.SimpleFold(rune(ch)); UTF32(r) != ch; r = unicode.SimpleFold(r) {
+		if UTF32(r) < lo {
+			lo = UTF32(r)
+		}
+	}
+	return lo
+}
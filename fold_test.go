@@ -0,0 +1,24 @@
+package utf32
+
+import "testing"
+
+func TestFoldingApply(t *testing.T) {
+	var tests = []struct {
+		folding Folding
+		str     string
+		expect  string
+	}{
+		{folding: 0, str: "Élan", expect: "Élan"},
+		// Kelvin sign.
+		{folding: FoldCase, str: "Élan K", expect: "ÉLAN K"},
+		{folding: FoldDiacritics, str: "Élan Ångström", expect: "Elan Angstrom"},
+		{folding: FoldCase | FoldDiacritics, str: "élan ǆ", expect: "ELAN Ǆ"},
+		// Hangul syllables are decomposed into jamo.
+		{folding: FoldCase | FoldDiacritics, str: "ø 가", expect: "Ø 가"},
+	}
+	for _, elem := range tests {
+		if expect, got := elem.expect, mustString(t, elem.folding.Apply(mustConvert(t, elem.str))); expect != got {
+			t.Fatalf("Unexpected result for %q.\nExpect:\t%s\nGot:\t%s\n", elem.str, expect, got)
+		}
+	}
+}
@@ -0,0 +1,316 @@
+package utf32
+
+import (
+	"errors"
+	"unicode"
+)
+
+// ErrBadPattern is returned for malformed glob patterns.
+var ErrBadPattern = errors.New("syntax error in pattern")
+
+// globSeparator separates path elements. Only "**" matches it.
+const globSeparator UTF32 = '/'
+
+type globKind uint8
+
+const (
+	globLiteral  globKind = iota
+	globAny               // ?
+	globClass             // [...]
+	globStar              // *
+	globStarStar          // ** inside an element, or at the end.
+	globDirs              // **/, followed by a globStarStar and a '/' literal.
+)
+
+type globToken struct {
+	kind  globKind
+	ch    UTF32
+	class *globCharClass
+}
+
+// globCharClass is a bracket expression.
+type globCharClass struct {
+	negate bool
+	ranges [][2]UTF32
+	tables []*unicode.RangeTable
+	// Tables given with \P{}, whose complement is in the class.
+	notTables []*unicode.RangeTable
+}
+
+// Glob is a compiled glob pattern matching []UTF32 paths.
+//
+// The pattern syntax is:
+//
+//	'*'        any sequence of code points other than '/'
+//	'**'       as a whole path element, any number of path elements;
+//	           elsewhere the same as '*'
+//	'?'        any code point other than '/'
+//	'[' ']'    any code point other than '/' in the class, negated with a
+//	           leading '!' or '^'. A class holds code points, ranges such
+//	           as a-z, and Unicode categories, scripts or properties as
+//	           \p{Greek} or \pL, and \P{...} for their complement
+//	'\\' c     the code point c
+type Glob struct {
+	tokens    []globToken
+	foldCase  bool
+	normalize bool
+}
+
+// GlobOption configures a Glob.
+type GlobOption func(*Glob)
+
+// GlobFoldCase makes the pattern match regardless of case, using the simple
+// case folding of FoldCase.
+func GlobFoldCase() GlobOption {
+	return func(g *Glob) { g.foldCase = true }
+}
+
+// GlobNormalize makes the pattern match canonically equivalent names alike,
+// by converting both to NFC. "é" then matches both U+00E9 and "e" followed
+// by U+0301, as file names on macOS and Linux spell it.
+func GlobNormalize() GlobOption {
+	return func(g *Glob) { g.normalize = true }
+}
+
+// CompileGlob parses pattern. It fails with ErrBadPattern if the pattern is
+// malformed.
+func CompileGlob(pattern []UTF32, opts ...GlobOption) (*Glob, error) {
+	g := &Glob{}
+	for _, opt := range opts {
+		opt(g)
+	}
+	if g.normalize {
+		pattern = NFC(pattern)
+	}
+	for i := 0; i < len(pattern); {
+		switch ch := pattern[i]; ch {
+		case '*':
+			n := 0
+			for i < len(pattern) && pattern[i] == '*' {
+				i++
+				n++
+			}
+			start := i-n == 0 || pattern[i-n-1] == globSeparator
+			end := i == len(pattern) || pattern[i] == globSeparator
+			switch {
+			case n < 2 || !start || !end:
+				g.tokens = append(g.tokens, globToken{kind: globStar})
+			case i < len(pattern):
+				g.tokens = append(g.tokens, globToken{kind: globDirs}, globToken{kind: globStarStar}, globToken{ch: globSeparator})
+				i++
+			default:
+				g.tokens = append(g.tokens, globToken{kind: globStarStar})
+			}
+		case '?':
+			g.tokens = append(g.tokens, globToken{kind: globAny})
+			i++
+		case '[':
+			class, n, err := parseGlobClass(pattern[i+1:])
+			if err != nil {
+				return nil, err
+			}
+			g.tokens = append(g.tokens, globToken{kind: globClass, class: class})
+			i += 1 + n
+		case '\\':
+			if i+1 == len(pattern) {
+				return nil, ErrBadPattern
+			}
+			g.tokens = append(g.tokens, globToken{ch: g.fold(pattern[i+1])})
+			i += 2
+		default:
+			g.tokens = append(g.tokens, globToken{ch: g.fold(ch)})
+			i++
+		}
+	}
+	return g, nil
+}
+
+// MatchGlob reports whether name matches pattern. It fails with
+// ErrBadPattern if the pattern is malformed.
+func MatchGlob(pattern, name []UTF32, opts ...GlobOption) (bool, error) {
+	g, err := CompileGlob(pattern, opts...)
+	if err != nil {
+		return false, err
+	}
+	return g.Match(name), nil
+}
+
+func (g *Glob) fold(ch UTF32) UTF32 {
+	if g.foldCase {
+		return foldCase(ch)
+	}
+	return ch
+}
+
+// parseGlobClass parses the bracket expression starting after the '[' of
+// p, returning the class and its length including the closing ']'.
+func parseGlobClass(p []UTF32) (*globCharClass, int, error) {
+	class := &globCharClass{}
+	i := 0
+	if i < len(p) && (p[i] == '!' || p[i] == '^') {
+		class.negate = true
+		i++
+	}
+	for first := true; ; first = false {
+		if i == len(p) {
+			return nil, 0, ErrBadPattern
+		}
+		if p[i] == ']' && !first {
+			return class, i + 1, nil
+		}
+		if p[i] == '\\' && i+1 < len(p) && (p[i+1] == 'p' || p[i+1] == 'P') {
+			table, n, err := parseGlobProperty(p[i+2:])
+			if err != nil {
+				return nil, 0, err
+			}
+			if p[i+1] == 'p' {
+				class.tables = append(class.tables, table)
+			} else {
+				class.notTables = append(class.notTables, table)
+			}
+			i += 2 + n
+			continue
+		}
+		lo, n, err := globClassChar(p[i:])
+		if err != nil {
+			return nil, 0, err
+		}
+		i += n
+		hi := lo
+		if i+1 < len(p) && p[i] == '-' && p[i+1] != ']' {
+			if hi, n, err = globClassChar(p[i+1:]); err != nil {
+				return nil, 0, err
+			}
+			if hi < lo {
+				return nil, 0, ErrBadPattern
+			}
+			i += 1 + n
+		}
+		class.ranges = append(class.ranges, [2]UTF32{lo, hi})
+	}
+}
+
+// globClassChar returns the possibly escaped code point starting p and its
+// length in the pattern.
+func globClassChar(p []UTF32) (UTF32, int, error) {
+	if p[0] != '\\' {
+		return p[0], 1, nil
+	}
+	if len(p) < 2 {
+		return 0, 0, ErrBadPattern
+	}
+	return p[1], 2, nil
+}
+
+// parseGlobProperty parses the name following \p, either a single letter or
+// a name in braces, returning its table and the length of the name.
+func parseGlobProperty(p []UTF32) (*unicode.RangeTable, int, error) {
+	if len(p) == 0 {
+		return nil, 0, ErrBadPattern
+	}
+	name, n := string(rune(p[0])), 1
+	if p[0] == '{' {
+		end := 1
+		for end < len(p) && p[end] != '}' {
+			end++
+		}
+		if end == len(p) {
+			return nil, 0, ErrBadPattern
+		}
+		buf := make([]rune, 0, end-1)
+		for _, ch := range p[1:end] {
+			buf = append(buf, rune(ch))
+		}
+		name, n = string(buf), end+1
+	}
+	for _, tables := range []map[string]*unicode.RangeTable{unicode.Categories, unicode.Scripts, unicode.Properties} {
+		if table, ok := tables[name]; ok {
+			return table, n, nil
+		}
+	}
+	return nil, 0, ErrBadPattern
+}
+
+// contains reports whether ch is in the class, ignoring negation.
+func (c *globCharClass) contains(ch UTF32) bool {
+	for _, r := range c.ranges {
+		if ch >= r[0] && ch <= r[1] {
+			return true
+		}
+	}
+	for _, table := range c.tables {
+		if unicode.Is(table, rune(ch)) {
+			return true
+		}
+	}
+	for _, table := range c.notTables {
+		if !unicode.Is(table, rune(ch)) {
+			return true
+		}
+	}
+	return false
+}
+
+// match reports whether ch matches the class, or any code point of its case
+// folding orbit if foldCase is set.
+func (c *globCharClass) match(ch UTF32, foldCase bool) bool {
+	found := c.contains(ch)
+	if foldCase {
+		for r := unicode.SimpleFold(rune(ch)); !found && UTF32(r) != ch; r = unicode.SimpleFold(r) {
+			found = c.contains(UTF32(r))
+		}
+	}
+	return found != c.negate
+}
+
+// Match reports whether the whole of name matches g.
+func (g *Glob) Match(name []UTF32) bool {
+	if g.normalize {
+		name = NFC(name)
+	}
+	folded := name
+	if g.foldCase {
+		folded = make([]UTF32, len(name))
+		for i, ch := range name {
+			folded[i] = foldCase(ch)
+		}
+	}
+
+	// memo[ti*(len(name)+1)+ni] caches whether name[ni:] matches
+	// tokens[ti:]: 0 unknown, 1 no, 2 yes.
+	stride := len(name) + 1
+	memo := make([]uint8, (len(g.tokens)+1)*stride)
+	var match func(ti, ni int) bool
+	match = func(ti, ni int) bool {
+		if ti == len(g.tokens) {
+			return ni == len(name)
+		}
+		if m := memo[ti*stride+ni]; m != 0 {
+			return m == 2
+		}
+		more := ni < len(name)
+		var ok bool
+		switch tok := g.tokens[ti]; tok.kind {
+		case globLiteral:
+			ok = more && folded[ni] == tok.ch && match(ti+1, ni+1)
+		case globAny:
+			ok = more && name[ni] != globSeparator && match(ti+1, ni+1)
+		case globClass:
+			ok = more && name[ni] != globSeparator && tok.class.match(name[ni], g.foldCase) && match(ti+1, ni+1)
+		case globStar:
+			ok = match(ti+1, ni) || (more && name[ni] != globSeparator && match(ti, ni+1))
+		case globStarStar:
+			ok = match(ti+1, ni) || (more && match(ti, ni+1))
+		case globDirs:
+			// No element at all, or any ending with the separator.
+			ok = match(ti+3, ni) || match(ti+1, ni)
+		}
+		if ok {
+			memo[ti*stride+ni] = 2
+		} else {
+			memo[ti*stride+ni] = 1
+		}
+		return ok
+	}
+	return match(0, 0)
+}
@@ -0,0 +1,68 @@
+package utf32
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestMatchGlob(t *testing.T) {
+	var tests = []struct {
+		pattern string
+		name    string
+		opts    []GlobOption
+		expect  bool
+	}{
+		{pattern: "*.txt", name: "notes.txt", expect: true},
+		{pattern: "*.txt", name: "dir/notes.txt", expect: false},
+		{pattern: "??.go", name: "日本.go", expect: true},
+		{pattern: "?.go", name: "日本.go", expect: false},
+		{pattern: "a/*/c", name: "a/b/c", expect: true},
+		{pattern: "a/*/c", name: "a/b/d/c", expect: false},
+		{pattern: "a/**/c", name: "a/c", expect: true},
+		{pattern: "a/**/c", name: "a/b/d/c", expect: true},
+		{pattern: "a/**/c", name: "a/bc", expect: false},
+		{pattern: "**/*.go", name: "main.go", expect: true},
+		{pattern: "**/*.go", name: "cmd/utf32/main.go", expect: true},
+		{pattern: "docs/**", name: "docs/a/b.md", expect: true},
+		{pattern: "a**b", name: "axb", expect: true},
+		{pattern: "a**b", name: "ax/b", expect: false},
+		{pattern: "[a-c]x", name: "bx", expect: true},
+		{pattern: "[!a-c]x", name: "bx", expect: false},
+		{pattern: "[^a-c]x", name: "dx", expect: true},
+		{pattern: "[]]", name: "]", expect: true},
+		{pattern: "[a-]", name: "-", expect: true},
+		{pattern: `\*`, name: "*", expect: true},
+		{pattern: `\*`, name: "x", expect: false},
+		{pattern: `[\p{Greek}]*`, name: "αβγ", expect: true},
+		{pattern: `[\p{Greek}]*`, name: "abc", expect: false},
+		{pattern: `[\pN]`, name: "٣", expect: true},
+		{pattern: `[\P{L}]`, name: "a", expect: false},
+		{pattern: `[\p{Han}\p{Hiragana}]?`, name: "日の", expect: true},
+		{pattern: "[!x]", name: "/", expect: false},
+		{pattern: "ÉTÉ.txt", name: "été.TXT", expect: false},
+		{pattern: "ÉTÉ.txt", name: "été.TXT", opts: []GlobOption{GlobFoldCase()}, expect: true},
+		{pattern: "[A-C]*", name: "büro", opts: []GlobOption{GlobFoldCase()}, expect: true},
+		{pattern: "[!a-c]*", name: "Büro", opts: []GlobOption{GlobFoldCase()}, expect: false},
+		{pattern: "café*", name: "cafe\u0301.txt", expect: false},
+		{pattern: "café*", name: "cafe\u0301.txt", opts: []GlobOption{GlobNormalize()}, expect: true},
+		{pattern: "cafe\u0301?txt", name: "café.txt", opts: []GlobOption{GlobNormalize()}, expect: true},
+		{pattern: "CAFÉ*", name: "cafe\u0301.txt", opts: []GlobOption{GlobNormalize(), GlobFoldCase()}, expect: true},
+	}
+	for _, elem := range tests {
+		got, err := MatchGlob(mustConvert(t, elem.pattern), mustConvert(t, elem.name), elem.opts...)
+		if err != nil {
+			t.Fatalf("Unexpected error for %q: %s", elem.pattern, err)
+		}
+		if expect := elem.expect; expect != got {
+			t.Fatalf("Unexpected result for %q and %q.\nExpect:\t%t\nGot:\t%t\n", elem.pattern, elem.name, expect, got)
+		}
+	}
+}
+
+func TestMatchGlobBadPattern(t *testing.T) {
+	for _, pattern := range []string{`[a`, `[]`, `[z-a]`, `x\`, `[\p{Nope}]`, `[\p{L]`, `[\`} {
+		if _, err := MatchGlob(mustConvert(t, pattern), nil); !errors.Is(err, ErrBadPattern) {
+			t.Fatalf("Unexpected error for %q.\nExpect:\t%s\nGot:\t%v\n", pattern, ErrBadPattern, err)
+		}
+	}
+}
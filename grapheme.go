@@ -0,0 +1,176 @@
+package utf32
+
+import "unicode"
+
+// Grapheme cluster break properties, as defined by UAX #29.
+type graphemeProperty int
+
+const (
+	gbOther graphemeProperty = iota
+	gbCR
+	gbLF
+	gbControl
+	gbExtend
+	gbZWJ
+	gbRegionalIndicator
+	gbPrepend
+	gbSpacingMark
+	gbL
+	gbV
+	gbT
+	gbLV
+	gbLVT
+)
+
+// Hangul syllable constants.
+const (
+	hangulSBase  UTF32 = 0xac00
+	hangulSCount UTF32 = 11172
+	hangulTCount UTF32 = 28
+)
+
+// Ranges of Extended_Pictographic code points. The Miscellaneous Symbols and
+// Dingbats blocks are taken as a whole.
+var extendedPictographic = [][2]UTF32{
+	{0x00A9, 0x00A9}, {0x00AE, 0x00AE}, {0x203C, 0x203C}, {0x2049, 0x2049},
+	{0x2122, 0x2122}, {0x2139, 0x2139}, {0x2194, 0x2199}, {0x21A9, 0x21AA},
+	{0x231A, 0x231B}, {0x2328, 0x2328}, {0x2388, 0x2388}, {0x23CF, 0x23CF},
+	{0x23E9, 0x23F3}, {0x23F8, 0x23FA}, {0x24C2, 0x24C2}, {0x25AA, 0x25AB},
+	{0x25B6, 0x25B6}, {0x25C0, 0x25C0}, {0x25FB, 0x25FE}, {0x2600, 0x27BF},
+	{0x2934, 0x2935}, {0x2B05, 0x2B07}, {0x2B1B, 0x2B1C}, {0x2B50, 0x2B50},
+	{0x2B55, 0x2B55}, {0x3030, 0x3030}, {0x303D, 0x303D}, {0x3297, 0x3297},
+	{0x3299, 0x3299}, {0x1F000, 0x1F0FF}, {0x1F10D, 0x1F10F}, {0x1F12F, 0x1F12F},
+	{0x1F16C, 0x1F171}, {0x1F17E, 0x1F17F}, {0x1F18E, 0x1F18E}, {0x1F191, 0x1F19A},
+	{0x1F1AD, 0x1F1E5}, {0x1F201, 0x1F20F}, {0x1F21A, 0x1F21A}, {0x1F22F, 0x1F22F},
+	{0x1F232, 0x1F23A}, {0x1F23C, 0x1F23F}, {0x1F249, 0x1F3FA}, {0x1F400, 0x1F53D},
+	{0x1F546, 0x1F64F}, {0x1F680, 0x1F6FF}, {0x1F774, 0x1F77F}, {0x1F7D5, 0x1F7FF},
+	{0x1F80C, 0x1F80F}, {0x1F848, 0x1F84F}, {0x1F85A, 0x1F85F}, {0x1F888, 0x1F88F},
+	{0x1F8AE, 0x1F8FF}, {0x1F90C, 0x1F93A}, {0x1F93C, 0x1F945}, {0x1F947, 0x1FAFF},
+	{0x1FC00, 0x1FFFD},
+}
+
+// inRanges reports whether ch is within one of the sorted, inclusive ranges.
+func inRanges(ch UTF32, ranges [][2]UTF32) bool {
+	lo, hi := 0, len(ranges)
+	for lo < hi {
+		m := lo + (hi-lo)/2
+		switch {
+		case ch < ranges[m][0]:
+			hi = m
+		case ch > ranges[m][1]:
+			lo = m + 1
+		default:
+			return true
+		}
+	}
+	return false
+}
+
+func isExtendedPictographic(ch UTF32) bool {
+	return inRanges(ch, extendedPictographic)
+}
+
+func lookupGraphemeProperty(ch UTF32) graphemeProperty {
+	r := rune(ch)
+	switch {
+	case ch == '\r':
+		return gbCR
+	case ch == '\n':
+		return gbLF
+	case ch == 0x200d:
+		return gbZWJ
+	case ch == 0x200c, ch >= 0x1f3fb && ch <= 0x1f3ff, ch >= 0xe0020 && ch <= 0xe007f:
+		return gbExtend
+	case ch >= 0x1f1e6 && ch <= 0x1f1ff:
+		return gbRegionalIndicator
+	case ch >= 0x1100 && ch <= 0x115f, ch >= 0xa960 && ch <= 0xa97c:
+		return gbL
+	case ch >= 0x1160 && ch <= 0x11a7, ch >= 0xd7b0 && ch <= 0xd7c6:
+		return gbV
+	case ch >= 0x11a8 && ch <= 0x11ff, ch >= 0xd7cb && ch <= 0xd7fb:
+		return gbT
+	case ch >= hangulSBase && ch < hangulSBase+hangulSCount:
+		if (ch-hangulSBase)%hangulTCount == 0 {
+			return gbLV
+		}
+		return gbLVT
+	case ch > UniMaxLegalUTF32:
+		return gbControl
+	case unicode.Is(unicode.Prepended_Concatenation_Mark, r):
+		return gbPrepend
+	case unicode.In(r, unicode.Mn, unicode.Me, unicode.Other_Grapheme_Extend):
+		return gbExtend
+	case unicode.Is(unicode.Mc, r):
+		return gbSpacingMark
+	case unicode.In(r, unicode.Cc, unicode.Cf, unicode.Zl, unicode.Zp, unicode.Cs):
+		return gbControl
+	}
+	return gbOther
+}
+
+// FirstGrapheme returns the length, in code points, of the extended grapheme
+// cluster at the start of src. It returns 0 if src is empty.
+func FirstGrapheme(src []UTF32) int {
+	if len(src) == 0 {
+		return 0
+	}
+	prev := lookupGraphemeProperty(src[0])
+	// Whether the cluster so far is an Extended_Pictographic followed by
+	// Extend* ZWJ (GB11), and how many regional indicators it holds (GB12).
+	pictographic := isExtendedPictographic(src[0])
+	riCount := 0
+	if prev == gbRegionalIndicator {
+		riCount = 1
+	}
+	for i := 1; i < len(src); i++ {
+		cur := lookupGraphemeProperty(src[i])
+		if graphemeBreak(prev, cur, pictographic, riCount, src[i]) {
+			return i
+		}
+		switch {
+		case cur == gbRegionalIndicator:
+			riCount++
+		case cur == gbExtend, cur == gbZWJ:
+		default:
+			pictographic = isExtendedPictographic(src[i])
+		}
+		prev = cur
+	}
+	return len(src)
+}
+
+func graphemeBreak(prev, cur graphemeProperty, pictographic bool, riCount int, ch UTF32) bool {
+	switch {
+	case prev == gbCR && cur == gbLF: // GB3
+		return false
+	case prev == gbCR, prev == gbLF, prev == gbControl: // GB4
+		return true
+	case cur == gbCR, cur == gbLF, cur == gbControl: // GB5
+		return true
+	case prev == gbL && (cur == gbL || cur == gbV || cur == gbLV || cur == gbLVT): // GB6
+		return false
+	case (prev == gbLV || prev == gbV) && (cur == gbV || cur == gbT): // GB7
+		return false
+	case (prev == gbLVT || prev == gbT) && cur == gbT: // GB8
+		return false
+	case cur == gbExtend, cur == gbZWJ, cur == gbSpacingMark, prev == gbPrepend: // GB9, GB9a, GB9b
+		return false
+	case prev == gbZWJ && pictographic && isExtendedPictographic(ch): // GB11
+		return false
+	case prev == gbRegionalIndicator && cur == gbRegionalIndicator: // GB12, GB13
+		return riCount%2 == 0
+	}
+	return true // GB999
+}
+
+// Graphemes splits src into extended grapheme clusters. The returned slices
+// share the backing array of src.
+func Graphemes(src []UTF32) [][]UTF32 {
+	var ret [][]UTF32
+	for len(src) > 0 {
+		n := FirstGrapheme(src)
+		ret = append(ret, src[:n:n])
+		src = src[n:]
+	}
+	return ret
+}
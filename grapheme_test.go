@@ -0,0 +1,34 @@
+package utf32
+
+import "testing"
+
+func TestGraphemes(t *testing.T) {
+	var tests = []struct {
+		str    string
+		expect []string
+	}{
+		{str: "", expect: nil},
+		{str: "abc", expect: []string{"a", "b", "c"}},
+		{str: "ée", expect: []string{"é", "e"}},
+		{str: "\r\n\n", expect: []string{"\r\n", "\n"}},
+		{str: "\U0001F468‍\U0001F469‍\U0001F467x", expect: []string{"\U0001F468‍\U0001F469‍\U0001F467", "x"}},
+		{str: "\U0001F44D\U0001F3FD", expect: []string{"\U0001F44D\U0001F3FD"}},
+		{str: "\U0001F1EB\U0001F1F7\U0001F1EF\U0001F1F5\U0001F1FA", expect: []string{"\U0001F1EB\U0001F1F7", "\U0001F1EF\U0001F1F5", "\U0001F1FA"}},
+		{str: "각각", expect: []string{"각", "각"}},
+		{str: "a‍\U0001F469", expect: []string{"a‍", "\U0001F469"}},
+	}
+	for _, elem := range tests {
+		var got []string
+		for _, cluster := range Graphemes(mustConvert(t, elem.str)) {
+			got = append(got, mustString(t, cluster))
+		}
+		if len(got) != len(elem.expect) {
+			t.Fatalf("Unexpected cluster count for %q.\nExpect:\t%q\nGot:\t%q\n", elem.str, elem.expect, got)
+		}
+		for i := range got {
+			if expect, got := elem.expect[i], got[i]; expect != got {
+				t.Fatalf("Unexpected cluster for %q.\nExpect:\t%q\nGot:\t%q\n", elem.str, expect, got)
+			}
+		}
+	}
+}
@@ -0,0 +1,196 @@
+package utf32
+
+import "unicode"
+
+// IdentCase is a naming convention for identifiers.
+type IdentCase int
+
+// IdentCase values.
+const (
+	CamelCase     IdentCase = iota // fooBarBaz
+	PascalCase                     // FooBarBaz
+	SnakeCase                      // foo_bar_baz
+	KebabCase                      // foo-bar-baz
+	ScreamingCase                  // FOO_BAR_BAZ
+)
+
+// Letter classes used to find word boundaries in identifiers.
+const (
+	identOther = iota
+	identUpper
+	identLower
+	identCaseless
+	identDigit
+	identMark
+)
+
+func identClass(ch UTF32) int {
+	r := rune(ch)
+	switch {
+	case ch > UniMaxLegalUTF32:
+		return identOther
+	case unicode.IsUpper(r), unicode.IsTitle(r):
+		return identUpper
+	case unicode.IsLower(r):
+		return identLower
+	case unicode.IsLetter(r):
+		return identCaseless
+	case unicode.IsNumber(r):
+		return identDigit
+	case unicode.IsMark(r):
+		return identMark
+	}
+	return identOther
+}
+
+// IdentWords splits the identifier src into words. Anything but letters,
+// marks and numbers separates words, as do case changes: before an
+// uppercase letter following a lowercase letter or a number, before the
+// last of a run of uppercase letters followed by a lowercase letter, as in
+// "HTTP|Server", and between letters with and without case, so that
+// caseless scripts such as Han stand apart from Latin words. The returned
+// slices share the backing array of src.
+func IdentWords(src []UTF32) [][]UTF32 {
+	var ret [][]UTF32
+	start := 0
+	prev := identOther
+	for i, ch := range src {
+		class := identClass(ch)
+		if class == identMark && prev != identOther {
+			continue
+		}
+		var boundary bool
+		switch {
+		case class == identOther, class == identMark:
+		case prev == identOther:
+			start = i
+		case class == identUpper && (prev == identLower || prev == identDigit):
+			boundary = true
+		case class == identUpper && prev == identUpper:
+			// Look past the marks of ch for a lowercase letter.
+			j := i + 1
+			for j < len(src) && identClass(src[j]) == identMark {
+				j++
+			}
+			boundary = j < len(src) && identClass(src[j]) == identLower
+		case (class == identCaseless) != (prev == identCaseless) && class != identDigit && prev != identDigit:
+			boundary = true
+		}
+		if boundary {
+			ret = append(ret, src[start:i:i])
+			start = i
+		}
+		if class == identOther || class == identMark {
+			if prev != identOther {
+				ret = append(ret, src[start:i:i])
+			}
+			prev = identOther
+			continue
+		}
+		prev = class
+	}
+	if prev != identOther {
+		ret = append(ret, src[start:len(src):len(src)])
+	}
+	return ret
+}
+
+// IdentOption configures ToIdentCase.
+type IdentOption func(*identConfig)
+
+type identConfig struct {
+	acronyms  map[string]bool
+	keepUpper bool
+}
+
+// IdentAcronyms sets words, such as "ID" or "HTTP", written in uppercase in
+// camelCase and PascalCase. Words are matched regardless of case.
+func IdentAcronyms(words ...string) IdentOption {
+	return func(c *identConfig) {
+		for _, w := range words {
+			if src, err := ConvertUTF8toUTF32(w); err == nil {
+				c.acronyms[string(clusterKey(mapCase(nil, src, unicode.ToUpper)))] = true
+			}
+		}
+	}
+}
+
+// IdentKeepUpper makes words of several letters written all in uppercase in
+// the source stay in uppercase in camelCase and PascalCase, as acronyms.
+func IdentKeepUpper() IdentOption {
+	return func(c *identConfig) { c.keepUpper = true }
+}
+
+// ToIdentCase converts the identifier src, split with IdentWords, to the
+// given case. Letters without case are left as is. In camelCase and
+// PascalCase, words that would not start with an uppercase letter, such as
+// words of a caseless script or numbers, are joined with '_' so that their
+// boundary is kept. By default, acronyms are cased as other words:
+// "HTTPServer" gives "httpServer" in camelCase.
+func ToIdentCase(src []UTF32, style IdentCase, opts ...IdentOption) []UTF32 {
+	c := identConfig{acronyms: map[string]bool{}}
+	for _, opt := range opts {
+		opt(&c)
+	}
+	ret := make([]UTF32, 0, len(src))
+	for i, word := range IdentWords(src) {
+		start := len(ret)
+		switch style {
+		case SnakeCase, KebabCase, ScreamingCase:
+			if i > 0 {
+				sep := UTF32('_')
+				if style == KebabCase {
+					sep = '-'
+				}
+				ret = append(ret, sep)
+				start++
+			}
+			if style == ScreamingCase {
+				ret = mapCase(ret, word, unicode.ToUpper)
+			} else {
+				ret = mapCase(ret, word, unicode.ToLower)
+			}
+			continue
+		}
+
+		switch {
+		case i == 0 && style == CamelCase:
+			ret = mapCase(ret, word, unicode.ToLower)
+		case c.isAcronym(word):
+			ret = mapCase(ret, word, unicode.ToUpper)
+		default:
+			ret = mapCase(ret, word, unicode.ToLower)
+			ret[start] = UTF32(unicode.ToTitle(rune(ret[start])))
+		}
+		if i > 0 && identClass(ret[start]) != identUpper {
+			ret = append(ret[:start+1], ret[start:]...)
+			ret[start] = '_'
+		}
+	}
+	return ret
+}
+
+// isAcronym reports whether word is to be written in uppercase.
+func (c *identConfig) isAcronym(word []UTF32) bool {
+	upper := mapCase(nil, word, unicode.ToUpper)
+	if c.acronyms[string(clusterKey(upper))] {
+		return true
+	}
+	if !c.keepUpper || len(word) < 2 {
+		return false
+	}
+	for _, ch := range word {
+		if class := identClass(ch); class != identUpper && class != identMark {
+			return false
+		}
+	}
+	return true
+}
+
+// mapCase appends src mapped by fn to dst.
+func mapCase(dst, src []UTF32, fn func(rune) rune) []UTF32 {
+	for _, ch := range src {
+		dst = append(dst, UTF32(fn(rune(ch))))
+	}
+	return dst
+}
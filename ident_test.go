@@ -0,0 +1,70 @@
+package utf32
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestIdentWords(t *testing.T) {
+	var tests = []struct {
+		str    string
+		expect string
+	}{
+		{str: "fooBarBaz", expect: "foo|Bar|Baz"},
+		{str: "HTTPServer", expect: "HTTP|Server"},
+		{str: "parseHTTP2Request", expect: "parse|HTTP2|Request"},
+		{str: "user_id", expect: "user|id"},
+		{str: "  --kebab-case--", expect: "kebab|case"},
+		{str: "ÉtéÀParis", expect: "Été|À|Paris"},
+		{str: "naïveCafé", expect: "naïve|Café"},
+		{str: "userName名前", expect: "user|Name|名前"},
+		{str: "名前_番号", expect: "名前|番号"},
+		{str: "straßeNummer", expect: "straße|Nummer"},
+		{str: "ǅemalAbc", expect: "ǅemal|Abc"},
+		{str: "vector3D", expect: "vector3|D"},
+		{str: "", expect: ""},
+	}
+	for _, elem := range tests {
+		var got []string
+		for _, w := range IdentWords(mustConvert(t, elem.str)) {
+			got = append(got, mustString(t, w))
+		}
+		if expect, got := elem.expect, strings.Join(got, "|"); expect != got {
+			t.Fatalf("Unexpected result for %q.\nExpect:\t%s\nGot:\t%s\n", elem.str, expect, got)
+		}
+	}
+}
+
+func TestToIdentCase(t *testing.T) {
+	acronyms := IdentAcronyms("id", "HTTP")
+	var tests = []struct {
+		str    string
+		style  IdentCase
+		opts   []IdentOption
+		expect string
+	}{
+		{str: "foo_bar_baz", style: CamelCase, expect: "fooBarBaz"},
+		{str: "foo_bar_baz", style: PascalCase, expect: "FooBarBaz"},
+		{str: "fooBarBaz", style: SnakeCase, expect: "foo_bar_baz"},
+		{str: "fooBarBaz", style: KebabCase, expect: "foo-bar-baz"},
+		{str: "fooBarBaz", style: ScreamingCase, expect: "FOO_BAR_BAZ"},
+		{str: "HTTPServer", style: CamelCase, expect: "httpServer"},
+		{str: "HTTPServer", style: PascalCase, expect: "HttpServer"},
+		{str: "http_server_id", style: PascalCase, opts: []IdentOption{acronyms}, expect: "HTTPServerID"},
+		{str: "http_server_id", style: CamelCase, opts: []IdentOption{acronyms}, expect: "httpServerID"},
+		{str: "HTTPServerUrl", style: PascalCase, opts: []IdentOption{IdentKeepUpper()}, expect: "HTTPServerUrl"},
+		{str: "été_à_paris", style: PascalCase, expect: "ÉtéÀParis"},
+		{str: "ÉtéÀParis", style: SnakeCase, expect: "été_à_paris"},
+		{str: "größe_maß", style: ScreamingCase, expect: "GRÖßE_MAß"},
+		{str: "user_名前_番号", style: CamelCase, expect: "user_名前_番号"},
+		{str: "名前_user", style: PascalCase, expect: "名前User"},
+		{str: "имя_пользователя", style: CamelCase, expect: "имяПользователя"},
+		{str: "ǆemal_name", style: PascalCase, expect: "ǅemalName"},
+		{str: "version_2_beta", style: CamelCase, expect: "version_2Beta"},
+	}
+	for _, elem := range tests {
+		if expect, got := elem.expect, mustString(t, ToIdentCase(mustConvert(t, elem.str), elem.style, elem.opts...)); expect != got {
+			t.Fatalf("Unexpected result for %q.\nExpect:\t%s\nGot:\t%s\n", elem.str, expect, got)
+		}
+	}
+}
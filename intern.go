@@ -0,0 +1,184 @@
+package utf32
+
+import (
+	"runtime"
+	"sync"
+	"weak"
+)
+
+// Number of shards of the interners, a power of two, and of sequences each
+// shard of an Interner can hand a Handle out for.
+const (
+	internShardBits = 5
+	internShards    = 1 << internShardBits
+	internShardSize = 1 << (32 - internShardBits)
+)
+
+// hashUTF32 returns a 64-bit hash of src, mixing one code point at a time
+// as a whole word rather than byte by byte.
+func hashUTF32(src []UTF32) uint64 {
+	h := uint64(len(src)) * 0x9e3779b97f4a7c15
+	for _, ch := range src {
+		h ^= uint64(ch)
+		h *= 0xff51afd7ed558ccd
+		h ^= h >> 32
+	}
+	h ^= h >> 29
+	h *= 0xc4ceb9fe1a85ec53
+	h ^= h >> 32
+	return h
+}
+
+// Handle identifies a sequence interned by an Interner.
+type Handle uint32
+
+// Interner deduplicates []UTF32 sequences, handing out a compact Handle for
+// each distinct one. Interned sequences are kept for the lifetime of the
+// Interner; see WeakInterner for one that forgets unused sequences.
+//
+// The zero value is ready to use. An Interner is safe for concurrent use:
+// sequences are spread over shards, each with its own lock. A shard holds
+// at most 2^27 sequences, so that handles fit in 32 bits, and Intern panics
+// past that.
+type Interner struct {
+	shards [internShards]internShard
+}
+
+type internShard struct {
+	mu    sync.RWMutex
+	index map[uint64][]Handle
+	seqs  [][]UTF32
+}
+
+// Intern returns the handle of s, interning a copy of it if it is new.
+func (in *Interner) Intern(s []UTF32) Handle {
+	h := hashUTF32(s)
+	shardIdx := h & (internShards - 1)
+	shard := &in.shards[shardIdx]
+
+	shard.mu.RLock()
+	handle, ok := shard.find(h, s)
+	shard.mu.RUnlock()
+	if ok {
+		return handle
+	}
+
+	shard.mu.Lock()
+	defer shard.mu.Unlock()
+	// Another goroutine may have interned s meanwhile.
+	if handle, ok := shard.find(h, s); ok {
+		return handle
+	}
+	if len(shard.seqs) == internShardSize {
+		panic("utf32: Interner shard full, too many distinct sequences for a 32-bit Handle")
+	}
+	if shard.index == nil {
+		shard.index = map[uint64][]Handle{}
+	}
+	handle = Handle(len(shard.seqs))<<internShardBits | Handle(shardIdx)
+	shard.seqs = append(shard.seqs, append([]UTF32(nil), s...))
+	shard.index[h] = append(shard.index[h], handle)
+	return handle
+}
+
+func (shard *internShard) find(h uint64, s []UTF32) (Handle, bool) {
+	for _, handle := range shard.index[h] {
+		if equal(shard.seqs[handle>>internShardBits], s) {
+			return handle, true
+		}
+	}
+	return 0, false
+}
+
+// Lookup returns the sequence of handle, which must come from the same
+// Interner. The result is shared and must not be modified.
+func (in *Interner) Lookup(handle Handle) []UTF32 {
+	shard := &in.shards[handle&(internShards-1)]
+	shard.mu.RLock()
+	defer shard.mu.RUnlock()
+	return shard.seqs[handle>>internShardBits]
+}
+
+// Len returns the number of distinct sequences interned.
+func (in *Interner) Len() int {
+	n := 0
+	for i := range in.shards {
+		in.shards[i].mu.RLock()
+		n += len(in.shards[i].seqs)
+		in.shards[i].mu.RUnlock()
+	}
+	return n
+}
+
+// Symbol is a sequence interned by a WeakInterner. Two symbols of the same
+// WeakInterner are equal if and only if their pointers are.
+type Symbol struct {
+	// Text is the interned sequence. It must not be modified.
+	Text []UTF32
+}
+
+// WeakInterner deduplicates []UTF32 sequences like Interner, but only holds
+// weak references to them: once no *Symbol of a sequence is reachable, the
+// garbage collector reclaims it and its entry is removed.
+//
+// The zero value is ready to use. A WeakInterner is safe for concurrent use.
+type WeakInterner struct {
+	shards [internShards]weakShard
+}
+
+type weakShard struct {
+	mu    sync.Mutex
+	index map[uint64][]weak.Pointer[Symbol]
+}
+
+// Intern returns the symbol of s, interning a copy of it if it is new or
+// was reclaimed.
+func (in *WeakInterner) Intern(s []UTF32) *Symbol {
+	h := hashUTF32(s)
+	shard := &in.shards[h&(internShards-1)]
+	shard.mu.Lock()
+	defer shard.mu.Unlock()
+	for _, wp := range shard.index[h] {
+		if sym := wp.Value(); sym != nil && equal(sym.Text, s) {
+			return sym
+		}
+	}
+	if shard.index == nil {
+		shard.index = map[uint64][]weak.Pointer[Symbol]{}
+	}
+	sym := &Symbol{Text: append([]UTF32(nil), s...)}
+	shard.index[h] = append(shard.index[h], weak.Make(sym))
+	runtime.AddCleanup(sym, shard.sweep, h)
+	return sym
+}
+
+// sweep removes the reclaimed symbols with hash h.
+func (shard *weakShard) sweep(h uint64) {
+	shard.mu.Lock()
+	defer shard.mu.Unlock()
+	live := shard.index[h][:0]
+	for _, wp := range shard.index[h] {
+		if wp.Value() != nil {
+			live = append(live, wp)
+		}
+	}
+	if len(live) == 0 {
+		delete(shard.index, h)
+		return
+	}
+	shard.index[h] = live
+}
+
+// Len returns the number of distinct sequences currently interned, counting
+// reclaimed ones whose entries were not removed yet.
+func (in *WeakInterner) Len() int {
+	n := 0
+	for i := range in.shards {
+		in.shards[i].mu.Lock()
+		for _, wps := range in.shards[i].index {
+			n += len(wps)
+		}
+		in.shards[i].mu.Unlock()
+	}
+	return n
+}
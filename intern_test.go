@@ -0,0 +1,87 @@
+package utf32
+
+import (
+	"runtime"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestInterner(t *testing.T) {
+	var in Interner
+	words := []string{"hello", "héllo", "日本", "", "𒔊", "hello"}
+	handles := make([]Handle, len(words))
+	for i, w := range words {
+		handles[i] = in.Intern(mustConvert(t, w))
+	}
+	if handles[0] != handles[5] {
+		t.Fatalf("Unexpected distinct handles for the same sequence: %d, %d\n", handles[0], handles[5])
+	}
+	if expect, got := 5, in.Len(); expect != got {
+		t.Fatalf("Unexpected length.\nExpect:\t%d\nGot:\t%d\n", expect, got)
+	}
+	for i, w := range words {
+		if expect, got := w, mustString(t, in.Lookup(handles[i])); expect != got {
+			t.Fatalf("Unexpected lookup.\nExpect:\t%q\nGot:\t%q\n", expect, got)
+		}
+	}
+
+	// The interned copy does not alias the caller's buffer.
+	buf := mustConvert(t, "abc")
+	h := in.Intern(buf)
+	buf[0] = 'x'
+	if expect, got := "abc", mustString(t, in.Lookup(h)); expect != got {
+		t.Fatalf("Unexpected lookup.\nExpect:\t%q\nGot:\t%q\n", expect, got)
+	}
+}
+
+func TestInternerConcurrent(t *testing.T) {
+	var in Interner
+	words := [][]UTF32{mustConvert(t, "a"), mustConvert(t, "b"), mustConvert(t, "日本"), mustConvert(t, "🎉")}
+	results := make([][]Handle, 8)
+	var wg sync.WaitGroup
+	for g := range results {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < 100; i++ {
+				for _, w := range words {
+					results[g] = append(results[g], in.Intern(w))
+				}
+			}
+		}(g)
+	}
+	wg.Wait()
+	for g := range results {
+		for i := range results[g] {
+			if results[g][i] != results[0][i] {
+				t.Fatalf("Unexpected handle mismatch between goroutines: %d, %d\n", results[g][i], results[0][i])
+			}
+		}
+	}
+	if expect, got := len(words), in.Len(); expect != got {
+		t.Fatalf("Unexpected length.\nExpect:\t%d\nGot:\t%d\n", expect, got)
+	}
+}
+
+func TestWeakInterner(t *testing.T) {
+	var in WeakInterner
+	a := in.Intern(mustConvert(t, "hello"))
+	if b := in.Intern(mustConvert(t, "hello")); a != b {
+		t.Fatal("Unexpected distinct symbols for the same sequence")
+	}
+	if c := in.Intern(mustConvert(t, "world")); c == a || mustString(t, c.Text) != "world" {
+		t.Fatalf("Unexpected symbol: %U\n", c.Text)
+	}
+
+	// Once "world" is unreachable, its entry goes away.
+	deadline := time.Now().Add(5 * time.Second)
+	for in.Len() != 1 {
+		if time.Now().After(deadline) {
+			t.Fatalf("Unexpected length after GC.\nExpect:\t1\nGot:\t%d\n", in.Len())
+		}
+		runtime.GC()
+		time.Sleep(time.Millisecond)
+	}
+	runtime.KeepAlive(a)
+}
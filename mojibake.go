@@ -0,0 +1,170 @@
+package utf32
+
+import (
+	"unicode"
+	"unicode/utf16"
+)
+
+// Code points of the Windows-1252 bytes 0x80 to 0x9F. The five undefined
+// bytes map to the C1 control of the same value, as most decoders do.
+var windows1252 = [32]UTF32{
+	0x20ac, 0x0081, 0x201a, 0x0192, 0x201e, 0x2026, 0x2020, 0x2021,
+	0x02c6, 0x2030, 0x0160, 0x2039, 0x0152, 0x008d, 0x017d, 0x008f,
+	0x0090, 0x2018, 0x2019, 0x201c, 0x201d, 0x2022, 0x2013, 0x2014,
+	0x02dc, 0x2122, 0x0161, 0x203a, 0x0153, 0x009d, 0x017e, 0x0178,
+}
+
+// Maximum number of repairs chained by FixMojibake.
+const maxMojibakeSteps = 4
+
+// MojibakeFix is the result of FixMojibake.
+type MojibakeFix struct {
+	// Text is the repaired text, or the original one if no repair helped.
+	Text []UTF32
+	// Steps describes each repair applied, in order, e.g.
+	// "encoded as windows-1252, decoded as utf-8".
+	Steps []string
+	// Badness scores how garbled Text still looks. 0 means no sign of
+	// mojibake was found.
+	Badness int
+}
+
+// FixMojibake detects text that was decoded with the wrong encoding, such
+// as "Ã©" for "é" (utf-8 read as windows-1252 or latin-1) or utf-16 read
+// as utf-32, and undoes it. Each candidate repair is scored for how garbled
+// it looks, and the best one is kept only if it scores strictly better than
+// the input. Repairs are chained to undo text encoded twice or more.
+func FixMojibake(src []UTF32) MojibakeFix {
+	ret := MojibakeFix{Text: src, Badness: mojibakeBadness(src)}
+	for len(ret.Steps) < maxMojibakeSteps && ret.Badness > 0 {
+		var (
+			best      []UTF32
+			bestStep  string
+			bestScore = ret.Badness
+		)
+		for _, candidate := range mojibakeCandidates {
+			text, ok := candidate.fix(ret.Text)
+			if !ok {
+				continue
+			}
+			if score := mojibakeBadness(text); score < bestScore {
+				best, bestStep, bestScore = text, candidate.step, score
+			}
+		}
+		if best == nil {
+			break
+		}
+		ret.Text, ret.Badness = best, bestScore
+		ret.Steps = append(ret.Steps, bestStep)
+	}
+	return ret
+}
+
+var mojibakeCandidates = []struct {
+	step string
+	fix  func([]UTF32) ([]UTF32, bool)
+}{
+	{step: "encoded as latin-1, decoded as utf-8", fix: func(src []UTF32) ([]UTF32, bool) {
+		return redecodeUTF8(src, false)
+	}},
+	{step: "encoded as windows-1252, decoded as utf-8", fix: func(src []UTF32) ([]UTF32, bool) {
+		return redecodeUTF8(src, true)
+	}},
+	{step: "split as utf-16le code units read as utf-32le", fix: func(src []UTF32) ([]UTF32, bool) {
+		return splitUTF16(src, false)
+	}},
+	{step: "split as utf-16be code units read as utf-32be", fix: func(src []UTF32) ([]UTF32, bool) {
+		return splitUTF16(src, true)
+	}},
+}
+
+// encodeWindows1252 returns the windows-1252 byte for ch. Latin-1 code
+// points, including the C1 controls, map to the byte of the same value.
+func encodeWindows1252(ch UTF32, cp1252 bool) (byte, bool) {
+	if cp1252 {
+		for i, c := range windows1252 {
+			if c == ch {
+				return byte(0x80 + i), true
+			}
+		}
+	}
+	if ch > 0xff {
+		return 0, false
+	}
+	return byte(ch), true
+}
+
+// redecodeUTF8 encodes src as latin-1 or windows-1252 and strictly decodes
+// the resulting bytes as utf-8.
+func redecodeUTF8(src []UTF32, cp1252 bool) ([]UTF32, bool) {
+	buf := make([]byte, 0, len(src))
+	for _, ch := range src {
+		b, ok := encodeWindows1252(ch, cp1252)
+		if !ok {
+			return nil, false
+		}
+		buf = append(buf, b)
+	}
+	str := string(buf)
+	ret := make([]UTF32, 0, len(str))
+	for i := 0; i < len(str); {
+		ch, size, ok := decodeUTF8(str, i)
+		if !ok {
+			return nil, false
+		}
+		ret = append(ret, ch)
+		i += size
+	}
+	return ret, true
+}
+
+// splitUTF16 splits each value of src into two utf-16 code units, low half
+// first unless bigEndian is set, and decodes them. A trailing NUL unit,
+// left over from an odd number of code units, is dropped.
+func splitUTF16(src []UTF32, bigEndian bool) ([]UTF32, bool) {
+	units := make([]uint16, 0, 2*len(src))
+	for _, ch := range src {
+		hi, lo := uint16(ch>>16), uint16(ch)
+		if bigEndian {
+			units = append(units, hi, lo)
+		} else {
+			units = append(units, lo, hi)
+		}
+	}
+	if len(units) > 0 && units[len(units)-1] == 0 {
+		units = units[:len(units)-1]
+	}
+	runes := utf16.Decode(units)
+	ret := make([]UTF32, 0, len(runes))
+	for _, r := range runes {
+		if r == 0 || r == unicode.ReplacementChar {
+			return nil, false
+		}
+		ret = append(ret, UTF32(r))
+	}
+	return ret, true
+}
+
+// mojibakeBadness scores how garbled src looks: invalid or unassigned code
+// points, C1 controls, and latin-1 letters followed by what would be a utf-8
+// continuation byte in windows-1252, the signature of utf-8 read as a
+// single-byte encoding.
+func mojibakeBadness(src []UTF32) int {
+	score := 0
+	for i, ch := range src {
+		r := rune(ch)
+		switch {
+		case ch > UniMaxLegalUTF32, ch >= UniSurHighStart && ch <= UniSurLowEnd:
+			score += 4
+		case ch >= 0x80 && ch < 0xa0:
+			score += 2
+		case ch == unicode.ReplacementChar, unicode.In(r, unicode.Co), !unicode.In(r, unicode.L, unicode.M, unicode.N, unicode.P, unicode.S, unicode.Z, unicode.C):
+			score += 3
+		case ch >= 0xc2 && ch <= 0xf4 && i+1 < len(src):
+			if b, ok := encodeWindows1252(src[i+1], true); ok && b >= 0x80 && b < 0xc0 {
+				score += 2
+			}
+		}
+	}
+	return score
+}
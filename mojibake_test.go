@@ -0,0 +1,31 @@
+package utf32
+
+import "testing"
+
+func TestFixMojibake(t *testing.T) {
+	var tests = []struct {
+		src    []UTF32
+		expect string
+		steps  int
+	}{
+		{src: mustConvert(t, "café"), expect: "café", steps: 0},
+		{src: mustConvert(t, "cafÃ©"), expect: "café", steps: 1},
+		{src: mustConvert(t, "donâ€™t"), expect: "don’t", steps: 1},
+		{src: mustConvert(t, "cafÃƒÂ©"), expect: "café", steps: 2},
+		{src: mustConvert(t, "Ð¿Ñ€Ð¸Ð²ÐµÑ‚"), expect: "привет", steps: 1},
+		{src: []UTF32{0x00690068, 0x00000021}, expect: "hi!", steps: 1},
+		{src: []UTF32{0x00680069, 0x00210000}, expect: "hi!", steps: 1},
+	}
+	for _, elem := range tests {
+		fix := FixMojibake(elem.src)
+		if expect, got := elem.expect, mustString(t, fix.Text); expect != got {
+			t.Fatalf("Unexpected result.\nExpect:\t%q\nGot:\t%q (%v)\n", expect, got, fix.Steps)
+		}
+		if expect, got := elem.steps, len(fix.Steps); expect != got {
+			t.Fatalf("Unexpected steps for %q.\nExpect:\t%d\nGot:\t%d (%v)\n", elem.expect, expect, got, fix.Steps)
+		}
+		if fix.Badness != 0 {
+			t.Fatalf("Unexpected badness for %q: %d\n", elem.expect, fix.Badness)
+		}
+	}
+}
@@ -0,0 +1,111 @@
+package utf32
+
+import "unicode"
+
+// NaturalCompare compares a and b in natural order: runs of decimal digits
+// are compared by numeric value, so "file2" sorts before "file10", and the
+// rest is compared in code point order. See NaturalCompareFunc.
+func NaturalCompare(a, b []UTF32) int {
+	return NaturalCompareFunc(a, b, Compare)
+}
+
+// NaturalCompareFunc is like NaturalCompare but compares the non-numeric
+// segments with collate, such as CompareUTF16 or a case-insensitive
+// comparison.
+//
+// Digits are the code points with Numeric_Type=Decimal, general category
+// Nd, in any script: Devanagari "२" or full-width "２" count as 2, and a
+// run may mix scripts. Numbers equal in value, such as "02" and "2", are
+// ordered by the rest of the text, then by collate over the whole of a and
+// b, then in code point order, so that only equal sequences compare equal.
+func NaturalCompareFunc(a, b []UTF32, collate func(a, b []UTF32) int) int {
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		ai, aNum := naturalSegment(a, i)
+		bj, bNum := naturalSegment(b, j)
+		var c int
+		if aNum && bNum {
+			c = compareNumbers(a[i:ai], b[j:bj])
+		} else {
+			c = collate(a[i:ai], b[j:bj])
+		}
+		if c != 0 {
+			return c
+		}
+		i, j = ai, bj
+	}
+	switch {
+	case i < len(a):
+		return 1
+	case j < len(b):
+		return -1
+	}
+	if c := collate(a, b); c != 0 {
+		return c
+	}
+	return Compare(a, b)
+}
+
+// naturalSegment returns the end of the segment of src starting at i, a run
+// of digits or of anything else, and whether it is a number.
+func naturalSegment(src []UTF32, i int) (int, bool) {
+	_, num := digitValue(src[i])
+	end := i + 1
+	for end < len(src) {
+		if _, ok := digitValue(src[end]); ok != num {
+			break
+		}
+		end++
+	}
+	return end, num
+}
+
+// compareNumbers compares two runs of digits by value.
+func compareNumbers(a, b []UTF32) int {
+	a, b = trimLeadingZeros(a), trimLeadingZeros(b)
+	switch {
+	case len(a) < len(b):
+		return -1
+	case len(a) > len(b):
+		return 1
+	}
+	for i := range a {
+		da, _ := digitValue(a[i])
+		db, _ := digitValue(b[i])
+		switch {
+		case da < db:
+			return -1
+		case da > db:
+			return 1
+		}
+	}
+	return 0
+}
+
+func trimLeadingZeros(digits []UTF32) []UTF32 {
+	for len(digits) > 0 {
+		if d, _ := digitValue(digits[0]); d != 0 {
+			break
+		}
+		digits = digits[1:]
+	}
+	return digits
+}
+
+// digitValue returns the value of the decimal digit ch. Unicode encodes the
+// decimal digits of each script as contiguous runs from 0 to 9, so the
+// value is the distance to the start of the run, modulo 10 for the
+// adjacent runs of the mathematical digits.
+func digitValue(ch UTF32) (int, bool) {
+	if ch >= '0' && ch <= '9' {
+		return int(ch - '0'), true
+	}
+	if ch < 0x80 || !unicode.Is(unicode.Nd, rune(ch)) {
+		return 0, false
+	}
+	start := ch
+	for unicode.Is(unicode.Nd, rune(start-1)) {
+		start--
+	}
+	return int(ch-start) % 10, true
+}
@@ -0,0 +1,56 @@
+package utf32
+
+import (
+	"sort"
+	"strings"
+	"testing"
+)
+
+func sortNatural(t *testing.T, in []string, collate func(a, b []UTF32) int) string {
+	t.Helper()
+	src := make([][]UTF32, len(in))
+	for i, s := range in {
+		src[i] = mustConvert(t, s)
+	}
+	sort.SliceStable(src, func(i, j int) bool { return NaturalCompareFunc(src[i], src[j], collate) < 0 })
+	out := make([]string, len(src))
+	for i, s := range src {
+		out[i] = mustString(t, s)
+	}
+	return strings.Join(out, " ")
+}
+
+func TestNaturalCompare(t *testing.T) {
+	var tests = []struct {
+		a, b   string
+		expect int
+	}{
+		{a: "file2", b: "file10", expect: -1},
+		{a: "file10", b: "file2", expect: 1},
+		{a: "file२", b: "file१०", expect: -1}, // Devanagari.
+		{a: "file２", b: "file10", expect: -1}, // Full-width.
+		{a: "file１0", b: "file10", expect: 1}, // Same value, mixed scripts.
+		{a: "file02", b: "file2", expect: -1},
+		{a: "file2", b: "file2", expect: 0},
+		{a: "file2b", b: "file02a", expect: 1},
+		{a: "a", b: "a1", expect: -1},
+		{a: "a1", b: "1a", expect: 1},
+		{a: "", b: "0", expect: -1},
+	}
+	for _, elem := range tests {
+		if expect, got := elem.expect, NaturalCompare(mustConvert(t, elem.a), mustConvert(t, elem.b)); expect != got {
+			t.Fatalf("Unexpected result for %q and %q.\nExpect:\t%d\nGot:\t%d\n", elem.a, elem.b, expect, got)
+		}
+	}
+}
+
+func TestNaturalCompareFunc(t *testing.T) {
+	in := []string{"Img12.png", "img10.png", "IMG2.png", "img1.png", "img𝟘𝟡.png"}
+	if expect, got := "IMG2.png Img12.png img1.png img𝟘𝟡.png img10.png", sortNatural(t, in, Compare); expect != got {
+		t.Fatalf("Unexpected result.\nExpect:\t%s\nGot:\t%s\n", expect, got)
+	}
+	foldCase := func(a, b []UTF32) int { return Compare(FoldCase.Apply(a), FoldCase.Apply(b)) }
+	if expect, got := "img1.png IMG2.png img𝟘𝟡.png img10.png Img12.png", sortNatural(t, in, foldCase); expect != got {
+		t.Fatalf("Unexpected result.\nExpect:\t%s\nGot:\t%s\n", expect, got)
+	}
+}
@@ -0,0 +1,197 @@
+package utf32
+
+import (
+	"sort"
+	"sync"
+)
+
+// Hangul conjoining jamo constants, used to decompose and compose Hangul
+// syllables algorithmically.
+const (
+	hangulLBase  UTF32 = 0x1100
+	hangulVBase  UTF32 = 0x1161
+	hangulTBase  UTF32 = 0x11a7
+	hangulLCount UTF32 = 19
+	hangulVCount UTF32 = 21
+	hangulNCount       = hangulVCount * hangulTCount
+)
+
+var (
+	compositionsOnce sync.Once
+	compositions     map[[2]UTF32]UTF32
+)
+
+// combiningClass returns the canonical combining class of ch.
+func combiningClass(ch UTF32) uint8 {
+	i := sort.Search(len(combiningClasses), func(i int) bool { return combiningClasses[i].hi >= ch })
+	if i < len(combiningClasses) && combiningClasses[i].lo <= ch {
+		return combiningClasses[i].class
+	}
+	return 0
+}
+
+// lookupDecomposition returns the canonical decomposition mapping of ch.
+func lookupDecomposition(ch UTF32) (decomposition, bool) {
+	i := sort.Search(len(canonicalDecompositions), func(i int) bool { return canonicalDecompositions[i].ch >= ch })
+	if i < len(canonicalDecompositions) && canonicalDecompositions[i].ch == ch {
+		return canonicalDecompositions[i], true
+	}
+	return decomposition{}, false
+}
+
+// lookupComposition returns the primary composite of a and b.
+func lookupComposition(a, b UTF32) (UTF32, bool) {
+	if a >= hangulLBase && a < hangulLBase+hangulLCount && b >= hangulVBase && b < hangulVBase+hangulVCount {
+		return hangulSBase + ((a-hangulLBase)*hangulVCount+b-hangulVBase)*hangulTCount, true
+	}
+	if a >= hangulSBase && a < hangulSBase+hangulSCount && (a-hangulSBase)%hangulTCount == 0 &&
+		b > hangulTBase && b < hangulTBase+hangulTCount {
+		return a + b - hangulTBase, true
+	}
+	compositionsOnce.Do(func() {
+		compositions = map[[2]UTF32]UTF32{}
+		for _, d := range canonicalDecompositions {
+			if d.compose {
+				compositions[[2]UTF32{d.a, d.b}] = d.ch
+			}
+		}
+	})
+	ch, ok := compositions[[2]UTF32{a, b}]
+	return ch, ok
+}
+
+// appendDecomposed appends the full canonical decomposition of ch to dst.
+func appendDecomposed(dst []UTF32, ch UTF32) []UTF32 {
+	if ch >= hangulSBase && ch < hangulSBase+hangulSCount {
+		s := ch - hangulSBase
+		dst = append(dst, hangulLBase+s/hangulNCount, hangulVBase+(s%hangulNCount)/hangulTCount)
+		if t := s % hangulTCount; t != 0 {
+			dst = append(dst, hangulTBase+t)
+		}
+		return dst
+	}
+	d, ok := lookupDecomposition(ch)
+	if !ok {
+		return append(dst, ch)
+	}
+	dst = appendDecomposed(dst, d.a)
+	if d.b != 0 {
+		dst = appendDecomposed(dst, d.b)
+	}
+	return dst
+}
+
+// NFD returns the canonical decomposition (Normalization Form D) of src.
+func NFD(src []UTF32) []UTF32 {
+	ret := make([]UTF32, 0, len(src))
+	for _, ch := range src {
+		ret = appendDecomposed(ret, ch)
+	}
+	// Canonical ordering: stable sort each run of non-starters by class.
+	for i := 0; i < len(ret); {
+		if combiningClass(ret[i]) == 0 {
+			i++
+			continue
+		}
+		j := i + 1
+		for j < len(ret) && combiningClass(ret[j]) != 0 {
+			j++
+		}
+		run := ret[i:j]
+		sort.SliceStable(run, func(a, b int) bool { return combiningClass(run[a]) < combiningClass(run[b]) })
+		i = j
+	}
+	return ret
+}
+
+// NFC returns the canonical composition (Normalization Form C) of src.
+func NFC(src []UTF32) []UTF32 {
+	ret := NFD(src)
+	if len(ret) == 0 {
+		return ret
+	}
+	starter, out := -1, 0
+	var lastClass uint8
+	for _, ch := range ret {
+		class := combiningClass(ch)
+		if starter >= 0 && (out == starter+1 || (lastClass != 0 && lastClass < class)) {
+			// ch is not blocked from the last starter.
+			if composite, ok := lookupComposition(ret[starter], ch); ok {
+				ret[starter] = composite
+				continue
+			}
+		}
+		if class == 0 {
+			starter = out
+		}
+		lastClass = class
+		ret[out] = ch
+		out++
+	}
+	return ret[:out]
+}
+
+// CanonicallyEquivalent reports whether a and b have the same canonical
+// decomposition, i.e. whether they should display and behave the same even
+// if their code points differ, as with "é" in NFC and NFD.
+func CanonicallyEquivalent(a, b []UTF32) bool {
+	return equal(NFD(a), NFD(b))
+}
+
+// NFDMap is like NFD but also returns the map between indices in src and
+// indices in the result. Each starter and the non-starters following it
+// form one segment, since canonical ordering moves marks within it.
+func NFDMap(src []UTF32) ([]UTF32, *OffsetMap) {
+	return normalizeMap(src, NFD)
+}
+
+// NFCMap is like NFC but also returns the map between indices in src and
+// indices in the result. Each starter and the non-starters following it
+// form one segment, merged with the previous one when they compose.
+func NFCMap(src []UTF32) ([]UTF32, *OffsetMap) {
+	return normalizeMap(src, NFC)
+}
+
+// normalizeMap applies normalize to each segment of src starting with a
+// starter, merging segments whose normalization depends on each other.
+func normalizeMap(src []UTF32, normalize func([]UTF32) []UTF32) ([]UTF32, *OffsetMap) {
+	m := &OffsetMap{}
+	var ret, pendingOut []UTF32
+	pendingStart := 0
+	for i := 0; i < len(src); {
+		j := i + 1
+		for j < len(src) && combiningClass(appendDecomposed(nil, src[j])[0]) != 0 {
+			j++
+		}
+		segment := normalize(src[i:j])
+		if i > 0 {
+			merged := normalize(src[pendingStart:j])
+			if !equal(merged, append(pendingOut[:len(pendingOut):len(pendingOut)], segment...)) {
+				pendingOut = merged
+				i = j
+				continue
+			}
+			ret = append(ret, pendingOut...)
+			m.add(i-pendingStart, len(pendingOut))
+		}
+		pendingStart, pendingOut = i, segment
+		i = j
+	}
+	if len(src) > 0 {
+		ret = append(ret, pendingOut...)
+		m.add(len(src)-pendingStart, len(pendingOut))
+	}
+	return ret, m
+}
+
+func equal(a, b []UTF32) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,35 @@
+package utf32
+
+import "testing"
+
+func TestNormalization(t *testing.T) {
+	var tests = []struct {
+		str      string
+		nfd, nfc string
+	}{
+		{str: "abc", nfd: "abc", nfc: "abc"},
+		{str: "é", nfd: "é", nfc: "é"},
+		{str: "é", nfd: "é", nfc: "é"},
+		{str: "ệ", nfd: "ệ", nfc: "ệ"},
+		{str: "ệ", nfd: "ệ", nfc: "ệ"},
+		{str: "한", nfd: "한", nfc: "한"},
+		{str: "Å", nfd: "Å", nfc: "Å"},
+		{str: "̈́", nfd: "̈́", nfc: "̈́"},
+		{str: "ą̨́", nfd: "ą̨́", nfc: "ą̨́"},
+	}
+	for _, elem := range tests {
+		src := mustConvert(t, elem.str)
+		if expect, got := elem.nfd, mustString(t, NFD(src)); expect != got {
+			t.Fatalf("Unexpected NFD of %q.\nExpect:\t%+q\nGot:\t%+q\n", elem.str, expect, got)
+		}
+		if expect, got := elem.nfc, mustString(t, NFC(src)); expect != got {
+			t.Fatalf("Unexpected NFC of %q.\nExpect:\t%+q\nGot:\t%+q\n", elem.str, expect, got)
+		}
+		if !CanonicallyEquivalent(src, mustConvert(t, elem.nfc)) {
+			t.Fatalf("Expected %q to be equivalent to %q\n", elem.str, elem.nfc)
+		}
+	}
+	if CanonicallyEquivalent(mustConvert(t, "é"), mustConvert(t, "e")) {
+		t.Fatal("Expected é and e not to be equivalent")
+	}
+}
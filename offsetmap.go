@@ -0,0 +1,117 @@
+package utf32
+
+import "sort"
+
+// OffsetMap maps positions between the source and the output of a
+// conversion. Offsets are counted in the units of each side: bytes for
+// utf-8 and indices for []UTF32.
+//
+// The conversion is recorded as a sequence of segments, each turning a span
+// of the source into a span of the output, such as the bytes of one utf-8
+// sequence into one UTF32. Runs of segments of the same shape are stored
+// once, so the map of mostly ASCII or mostly single-script text is small.
+type OffsetMap struct {
+	runs   []offsetRun
+	srcLen int
+	dstLen int
+}
+
+// offsetRun is a run of count segments, each mapping srcStep source units
+// to dstStep output units, starting at src and dst.
+type offsetRun struct {
+	src, dst         int
+	srcStep, dstStep int
+	count            int
+}
+
+// add records a segment of srcStep source units converted to dstStep
+// output units.
+func (m *OffsetMap) add(srcStep, dstStep int) {
+	if n := len(m.runs); n > 0 && m.runs[n-1].srcStep == srcStep && m.runs[n-1].dstStep == dstStep {
+		m.runs[n-1].count++
+	} else {
+		m.runs = append(m.runs, offsetRun{src: m.srcLen, dst: m.dstLen, srcStep: srcStep, dstStep: dstStep, count: 1})
+	}
+	m.srcLen += srcStep
+	m.dstLen += dstStep
+}
+
+// SrcLen returns the length of the source.
+func (m *OffsetMap) SrcLen() int { return m.srcLen }
+
+// DstLen returns the length of the output.
+func (m *OffsetMap) DstLen() int { return m.dstLen }
+
+// SrcToDst returns the output offset of the segment holding source offset
+// off. Offsets inside a segment map to its start, offsets past the end of
+// the source map to the end of the output.
+func (m *OffsetMap) SrcToDst(off int) int {
+	if off <= 0 {
+		return 0
+	}
+	if off >= m.srcLen {
+		return m.dstLen
+	}
+	// Find the last run starting at or before off that is not empty.
+	i := sort.Search(len(m.runs), func(i int) bool { return m.runs[i].src > off }) - 1
+	for m.runs[i].srcStep == 0 {
+		i--
+	}
+	r := m.runs[i]
+	k := (off - r.src) / r.srcStep
+	if k >= r.count {
+		k = r.count - 1
+	}
+	return r.dst + k*r.dstStep
+}
+
+// DstToSrc returns the source offset of the segment holding output offset
+// off. Offsets inside a segment map to its start, offsets past the end of
+// the output map to the end of the source.
+func (m *OffsetMap) DstToSrc(off int) int {
+	if off <= 0 {
+		return 0
+	}
+	if off >= m.dstLen {
+		return m.srcLen
+	}
+	i := sort.Search(len(m.runs), func(i int) bool { return m.runs[i].dst > off }) - 1
+	for m.runs[i].dstStep == 0 {
+		i--
+	}
+	r := m.runs[i]
+	k := (off - r.dst) / r.dstStep
+	if k >= r.count {
+		k = r.count - 1
+	}
+	return r.src + k*r.srcStep
+}
+
+// ConvertUTF8toUTF32Map is like ConvertUTF8toUTF32 but also returns the
+// map between byte offsets in src and indices in the result.
+func ConvertUTF8toUTF32Map(src string) ([]UTF32, *OffsetMap, error) {
+	m := &OffsetMap{}
+	ret, err := convertUTF8toUTF32(src, m)
+	if err != nil {
+		return nil, nil, err
+	}
+	return ret, m, nil
+}
+
+// ConvertUTF8toUTF32EscapeMap is like ConvertUTF8toUTF32Escape but also
+// returns the map between byte offsets in src and indices in the result.
+func ConvertUTF8toUTF32EscapeMap(src string) ([]UTF32, *OffsetMap) {
+	m := &OffsetMap{}
+	return convertUTF8toUTF32Escape(src, m), m
+}
+
+// ConvertUTF32toUTF8Map is like ConvertUTF32toUTF8 but also returns the
+// map between indices in src and byte offsets in the result.
+func ConvertUTF32toUTF8Map(src []UTF32) (string, *OffsetMap, error) {
+	m := &OffsetMap{}
+	ret, err := convertUTF32toUTF8(src, m)
+	if err != nil {
+		return "", nil, err
+	}
+	return ret, m, nil
+}
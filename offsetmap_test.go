@@ -0,0 +1,116 @@
+package utf32
+
+import "testing"
+
+func TestOffsetMapUTF8(t *testing.T) {
+	str := "aé日𒔊b"
+	utf32, m, err := ConvertUTF8toUTF32Map(str)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expect, got := 5, len(utf32); expect != got {
+		t.Fatalf("Unexpected length.\nExpect:\t%d\nGot:\t%d\n", expect, got)
+	}
+	// Byte offset of each code point, and of the end.
+	starts := []int{0, 1, 3, 6, 10, 11}
+	for i, off := range starts {
+		if expect, got := off, m.DstToSrc(i); expect != got {
+			t.Fatalf("Unexpected source offset of %d.\nExpect:\t%d\nGot:\t%d\n", i, expect, got)
+		}
+		if expect, got := i, m.SrcToDst(off); expect != got {
+			t.Fatalf("Unexpected output offset of %d.\nExpect:\t%d\nGot:\t%d\n", off, expect, got)
+		}
+	}
+	// Offsets inside a sequence map to its start.
+	if expect, got := 3, m.SrcToDst(8); expect != got {
+		t.Fatalf("Unexpected output offset of 8.\nExpect:\t%d\nGot:\t%d\n", expect, got)
+	}
+
+	back, m2, err := ConvertUTF32toUTF8Map(utf32)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if back != str {
+		t.Fatalf("Unexpected round trip.\nExpect:\t%q\nGot:\t%q\n", str, back)
+	}
+	for i, off := range starts {
+		if expect, got := off, m2.SrcToDst(i); expect != got {
+			t.Fatalf("Unexpected byte offset of %d.\nExpect:\t%d\nGot:\t%d\n", i, expect, got)
+		}
+	}
+}
+
+func TestOffsetMapEscape(t *testing.T) {
+	utf32, m := ConvertUTF8toUTF32EscapeMap("a\xffé")
+	if expect, got := 3, len(utf32); expect != got {
+		t.Fatalf("Unexpected length.\nExpect:\t%d\nGot:\t%d\n", expect, got)
+	}
+	if expect, got := 2, m.DstToSrc(2); expect != got {
+		t.Fatalf("Unexpected source offset.\nExpect:\t%d\nGot:\t%d\n", expect, got)
+	}
+	if expect, got := 4, m.SrcLen(); expect != got {
+		t.Fatalf("Unexpected source length.\nExpect:\t%d\nGot:\t%d\n", expect, got)
+	}
+}
+
+func TestOffsetMapNormalization(t *testing.T) {
+	// "e" + acute, "x", Hangul L + V + T, "ệ".
+	src := []UTF32{'e', 0x301, 'x', 0x1112, 0x1161, 0x11ab, 0x1ec7}
+	nfc, m := NFCMap(src)
+	if expect, got := "éx한ệ", mustString(t, nfc); expect != got {
+		t.Fatalf("Unexpected NFC.\nExpect:\t%q\nGot:\t%q\n", expect, got)
+	}
+	for _, elem := range []struct{ src, dst int }{{0, 0}, {1, 0}, {2, 1}, {3, 2}, {5, 2}, {6, 3}, {7, 4}} {
+		if expect, got := elem.dst, m.SrcToDst(elem.src); expect != got {
+			t.Fatalf("Unexpected NFC offset of %d.\nExpect:\t%d\nGot:\t%d\n", elem.src, expect, got)
+		}
+	}
+
+	nfd, m := NFDMap(nfc)
+	if !equal(nfd, NFD(src)) {
+		t.Fatalf("Unexpected NFD.\nExpect:\t%U\nGot:\t%U\n", NFD(src), nfd)
+	}
+	for _, elem := range []struct{ dst, src int }{{0, 0}, {1, 0}, {2, 1}, {3, 2}, {5, 2}, {6, 3}, {8, 3}, {9, 4}} {
+		if expect, got := elem.src, m.DstToSrc(elem.dst); expect != got {
+			t.Fatalf("Unexpected NFD offset of %d.\nExpect:\t%d\nGot:\t%d\n", elem.dst, expect, got)
+		}
+	}
+}
+
+func TestOffsetMapConverter(t *testing.T) {
+	// The truncated 3-byte sequence is replaced by a single U+FFFD.
+	c := NewConverter(WithReplacement(0xfffd))
+	utf32, m, err := c.DecodeMap("a\xe2\x82bé")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expect, got := []UTF32{'a', 0xfffd, 'b', 0xe9}, utf32; !equal(expect, got) {
+		t.Fatalf("Unexpected result.\nExpect:\t%U\nGot:\t%U\n", expect, got)
+	}
+	for _, elem := range []struct{ src, dst int }{{0, 0}, {1, 1}, {2, 1}, {3, 2}, {4, 3}, {6, 4}} {
+		if expect, got := elem.dst, m.SrcToDst(elem.src); expect != got {
+			t.Fatalf("Unexpected output offset of %d.\nExpect:\t%d\nGot:\t%d\n", elem.src, expect, got)
+		}
+	}
+	if expect, got := 3, m.DstToSrc(2); expect != got {
+		t.Fatalf("Unexpected source offset.\nExpect:\t%d\nGot:\t%d\n", expect, got)
+	}
+
+	// The surrogate and the noncharacter both take three bytes as U+FFFD.
+	c = NewConverter(WithReplacement(0xfffd), WithNoncharacters(NoncharactersReplace))
+	str, m, err := c.EncodeMap([]UTF32{'a', 0xd800, 0xfdd0, 'b'})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expect, got := "a\ufffd\ufffdb", str; expect != got {
+		t.Fatalf("Unexpected result.\nExpect:\t%q\nGot:\t%q\n", expect, got)
+	}
+	for i, off := range []int{0, 1, 4, 7, 8} {
+		if expect, got := off, m.SrcToDst(i); expect != got {
+			t.Fatalf("Unexpected byte offset of %d.\nExpect:\t%d\nGot:\t%d\n", i, expect, got)
+		}
+	}
+	if _, _, err := NewConverter(WithStrict()).EncodeMap([]UTF32{0xd800}); err != ErrInvalidSource {
+		t.Fatalf("Unexpected error.\nExpect:\t%v\nGot:\t%v\n", ErrInvalidSource, err)
+	}
+}
@@ -0,0 +1,102 @@
+package utf32
+
+// Packed is an immutable sequence of code points stored in 1, 2 or 3 bytes
+// each, instead of the 4 of []UTF32. As in Python's PEP 393, the width is
+// chosen per sequence as the smallest one fitting its largest code point:
+// 1 byte up to U+00FF, 2 up to U+FFFF, 3 otherwise. Random access stays
+// O(1).
+//
+// The zero value is an empty sequence.
+type Packed struct {
+	data  []byte
+	width int
+}
+
+// Pack returns the packed form of src. It fails with ErrInvalidSource if
+// src holds an invalid code point.
+func Pack(src []UTF32) (Packed, error) {
+	var maxCh UTF32
+	for _, ch := range src {
+		if _, err := defaultConverter.check(ch); err != nil {
+			return Packed{}, err
+		}
+		if ch > maxCh {
+			maxCh = ch
+		}
+	}
+	p := Packed{width: 1}
+	switch {
+	case maxCh > 0xffff:
+		p.width = 3
+	case maxCh > 0xff:
+		p.width = 2
+	}
+	p.data = make([]byte, 0, p.width*len(src))
+	for _, ch := range src {
+		switch p.width {
+		case 1:
+			p.data = append(p.data, byte(ch))
+		case 2:
+			p.data = append(p.data, byte(ch), byte(ch>>8))
+		default:
+			p.data = append(p.data, byte(ch), byte(ch>>8), byte(ch>>16))
+		}
+	}
+	return p, nil
+}
+
+// PackString returns the packed form of the given utf-8 string.
+func PackString(s string) (Packed, error) {
+	src, err := ConvertUTF8toUTF32(s)
+	if err != nil {
+		return Packed{}, err
+	}
+	return Pack(src)
+}
+
+// Len returns the number of code points of p.
+func (p Packed) Len() int {
+	if p.width == 0 {
+		return 0
+	}
+	return len(p.data) / p.width
+}
+
+// Width returns the number of bytes used per code point, 0 for the zero
+// value.
+func (p Packed) Width() int {
+	return p.width
+}
+
+// At returns the i-th code point of p. It panics if i is out of range.
+func (p Packed) At(i int) UTF32 {
+	switch p.width {
+	case 1:
+		return UTF32(p.data[i])
+	case 2:
+		return UTF32(p.data[2*i]) | UTF32(p.data[2*i+1])<<8
+	}
+	i *= 3
+	return UTF32(p.data[i]) | UTF32(p.data[i+1])<<8 | UTF32(p.data[i+2])<<16
+}
+
+// Slice returns the code points of p from i to j, sharing its storage.
+func (p Packed) Slice(i, j int) Packed {
+	return Packed{data: p.data[i*p.width : j*p.width], width: p.width}
+}
+
+// Unpack returns the code points of p.
+func (p Packed) Unpack() []UTF32 {
+	ret := make([]UTF32, p.Len())
+	for i := range ret {
+		ret[i] = p.At(i)
+	}
+	return ret
+}
+
+// String returns p as utf-8.
+func (p Packed) String() string {
+	// p only holds valid code points.
+	ret, _ := ConvertUTF32toUTF8(p.Unpack())
+	return ret
+}
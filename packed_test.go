@@ -0,0 +1,51 @@
+package utf32
+
+import "testing"
+
+func TestPacked(t *testing.T) {
+	var tests = []struct {
+		str   string
+		width int
+	}{
+		{str: "", width: 1},
+		{str: "hello", width: 1},
+		{str: "café", width: 1},
+		{str: "Јазик 日本", width: 2},
+		{str: "a𒔊b", width: 3},
+	}
+	for _, elem := range tests {
+		p, err := PackString(elem.str)
+		if err != nil {
+			t.Fatal(err)
+		}
+		src := mustConvert(t, elem.str)
+		if expect, got := elem.width, p.Width(); expect != got {
+			t.Fatalf("Unexpected width for %q.\nExpect:\t%d\nGot:\t%d\n", elem.str, expect, got)
+		}
+		if expect, got := len(src), p.Len(); expect != got {
+			t.Fatalf("Unexpected length for %q.\nExpect:\t%d\nGot:\t%d\n", elem.str, expect, got)
+		}
+		for i, ch := range src {
+			if expect, got := ch, p.At(i); expect != got {
+				t.Fatalf("Unexpected code point %d of %q.\nExpect:\t%U\nGot:\t%U\n", i, elem.str, expect, got)
+			}
+		}
+		if !equal(src, p.Unpack()) {
+			t.Fatalf("Unexpected unpacked value for %q: %U\n", elem.str, p.Unpack())
+		}
+		if expect, got := elem.str, p.String(); expect != got {
+			t.Fatalf("Unexpected string.\nExpect:\t%q\nGot:\t%q\n", expect, got)
+		}
+	}
+
+	p, _ := PackString("a𒔊bc")
+	if expect, got := "𒔊b", p.Slice(1, 3).String(); expect != got {
+		t.Fatalf("Unexpected slice.\nExpect:\t%q\nGot:\t%q\n", expect, got)
+	}
+	if _, err := Pack([]UTF32{'a', 0x110000}); err != ErrInvalidSource {
+		t.Fatalf("Unexpected error.\nExpect:\t%v\nGot:\t%v\n", ErrInvalidSource, err)
+	}
+	if (Packed{}).Len() != 0 || (Packed{}).String() != "" {
+		t.Fatal("Unexpected zero value")
+	}
+}
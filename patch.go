@@ -0,0 +1,176 @@
+package utf32
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Patch errors.
+var (
+	ErrInvalidPatch  = errors.New("invalid patch")
+	ErrPatchMismatch = errors.New("patch does not apply")
+)
+
+// Hunk is a group of nearby edits with surrounding context. Positions and
+// lengths are counted in code points, starting at 0.
+type Hunk struct {
+	Start1, Len1 int // Span of the hunk in the old text.
+	Start2, Len2 int // Span of the hunk in the new text.
+	Diffs        []Diff
+}
+
+// MakePatch groups diffs into hunks, keeping up to context code points of
+// unchanged text around each edit. Edits separated by no more than twice
+// the context share a hunk.
+func MakePatch(diffs []Diff, context int) []Hunk {
+	var (
+		ret       []Hunk
+		cur       *Hunk
+		pos1      int
+		pos2      int
+		closeHunk = func(h *Hunk) {
+			for _, d := range h.Diffs {
+				if d.Op != DiffInsert {
+					h.Len1 += len(d.Text)
+				}
+				if d.Op != DiffDelete {
+					h.Len2 += len(d.Text)
+				}
+			}
+			ret = append(ret, *h)
+		}
+	)
+	for i, d := range diffs {
+		if d.Op != DiffEqual {
+			if cur == nil {
+				// Open a hunk with the tail of the previous equality.
+				cur = &Hunk{Start1: pos1, Start2: pos2}
+				if i > 0 && diffs[i-1].Op == DiffEqual {
+					text := diffs[i-1].Text
+					if len(text) > context {
+						text = text[len(text)-context:]
+					}
+					cur.Start1 -= len(text)
+					cur.Start2 -= len(text)
+					cur.Diffs = appendDiff(cur.Diffs, DiffEqual, text)
+				}
+			}
+			cur.Diffs = appendDiff(cur.Diffs, d.Op, d.Text)
+		} else if cur != nil {
+			if len(d.Text) > 2*context || i == len(diffs)-1 {
+				text := d.Text
+				if len(text) > context {
+					text = text[:context]
+				}
+				cur.Diffs = appendDiff(cur.Diffs, DiffEqual, text)
+				closeHunk(cur)
+				cur = nil
+			} else {
+				cur.Diffs = appendDiff(cur.Diffs, DiffEqual, d.Text)
+			}
+		}
+		if d.Op != DiffInsert {
+			pos1 += len(d.Text)
+		}
+		if d.Op != DiffDelete {
+			pos2 += len(d.Text)
+		}
+	}
+	if cur != nil {
+		closeHunk(cur)
+	}
+	return ret
+}
+
+// FormatPatch renders hunks as text. Each hunk starts with a
+// "@@ -start1,len1 +start2,len2 @@" header, followed by one line per
+// operation: a space, "-" or "+" and the Go-quoted utf-8 text. Lone
+// surrogates in U+DC80 to U+DCFF are written as the raw bytes they escape.
+func FormatPatch(hunks []Hunk) (string, error) {
+	var sb strings.Builder
+	for _, h := range hunks {
+		fmt.Fprintf(&sb, "@@ -%d,%d +%d,%d @@\n", h.Start1, h.Len1, h.Start2, h.Len2)
+		for _, d := range h.Diffs {
+			str, err := ConvertUTF32toUTF8Escape(d.Text)
+			if err != nil {
+				return "", err
+			}
+			sb.WriteString(string(" -+"[d.Op]))
+			sb.WriteString(strconv.Quote(str))
+			sb.WriteByte('\n')
+		}
+	}
+	return sb.String(), nil
+}
+
+// ParsePatch parses text produced by FormatPatch.
+func ParsePatch(text string) ([]Hunk, error) {
+	var ret []Hunk
+	scanner := bufio.NewScanner(strings.NewReader(text))
+	scanner.Buffer(nil, len(text)+1)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if strings.HasPrefix(line, "@@") {
+			var h Hunk
+			if _, err := fmt.Sscanf(line, "@@ -%d,%d +%d,%d @@", &h.Start1, &h.Len1, &h.Start2, &h.Len2); err != nil {
+				return nil, fmt.Errorf("%w: %q", ErrInvalidPatch, line)
+			}
+			ret = append(ret, h)
+			continue
+		}
+		if len(ret) == 0 || line == "" || strings.IndexByte(" -+", line[0]) < 0 {
+			return nil, fmt.Errorf("%w: %q", ErrInvalidPatch, line)
+		}
+		op := strings.IndexByte(" -+", line[0])
+		str, err := strconv.Unquote(line[1:])
+		if err != nil {
+			return nil, fmt.Errorf("%w: %q", ErrInvalidPatch, line)
+		}
+		h := &ret[len(ret)-1]
+		h.Diffs = append(h.Diffs, Diff{Op: DiffOp(op), Text: ConvertUTF8toUTF32Escape(str)})
+	}
+	return ret, scanner.Err()
+}
+
+// ApplyPatch applies hunks to src. The deleted text and context of each hunk
+// must be found at its Start1 position, or ErrPatchMismatch is returned.
+func ApplyPatch(src []UTF32, hunks []Hunk) ([]UTF32, error) {
+	var ret []UTF32
+	pos := 0
+	for _, h := range hunks {
+		if h.Start1 < pos || h.Start1 > len(src) {
+			return nil, ErrPatchMismatch
+		}
+		ret = append(ret, src[pos:h.Start1]...)
+		pos = h.Start1
+		for _, d := range h.Diffs {
+			if d.Op == DiffInsert {
+				ret = append(ret, d.Text...)
+				continue
+			}
+			if !hasPrefix(src[pos:], d.Text) {
+				return nil, ErrPatchMismatch
+			}
+			if d.Op == DiffEqual {
+				ret = append(ret, d.Text...)
+			}
+			pos += len(d.Text)
+		}
+	}
+	return append(ret, src[pos:]...), nil
+}
+
+func hasPrefix(src, prefix []UTF32) bool {
+	if len(prefix) > len(src) {
+		return false
+	}
+	for i := range prefix {
+		if src[i] != prefix[i] {
+			return false
+		}
+	}
+	return true
+}
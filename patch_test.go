@@ -0,0 +1,49 @@
+package utf32
+
+import "testing"
+
+func TestPatch(t *testing.T) {
+	a := mustConvert(t, "Le café est chaud.\nНа улице холодно.\n東京は晴れです。")
+	b := mustConvert(t, "Le thé est chaud.\nНа улице тепло.\n東京は晴れです。")
+	hunks := MakePatch(CleanupSemantic(DiffCodePoints(a, b)), 4)
+	if expect, got := 2, len(hunks); expect != got {
+		t.Fatalf("Unexpected hunk count.\nExpect:\t%d\nGot:\t%d\n", expect, got)
+	}
+
+	text, err := FormatPatch(hunks)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expect := "" +
+		"@@ -0,10 +0,9 @@\n" +
+		" \"Le \"\n" +
+		"-\"caf\"\n" +
+		"+\"th\"\n" +
+		" \"é es\"\n" +
+		"@@ -24,14 +23,12 @@\n" +
+		" \"ице \"\n" +
+		"-\"холодн\"\n" +
+		"+\"тепл\"\n" +
+		" \"о.\\n東\"\n"
+	if got := text; expect != got {
+		t.Fatalf("Unexpected patch.\nExpect:\n%s\nGot:\n%s\n", expect, got)
+	}
+
+	parsed, err := ParsePatch(text)
+	if err != nil {
+		t.Fatal(err)
+	}
+	patched, err := ApplyPatch(a, parsed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expect, got := mustString(t, b), mustString(t, patched); expect != got {
+		t.Fatalf("Unexpected result.\nExpect:\t%q\nGot:\t%q\n", expect, got)
+	}
+	if _, err := ApplyPatch(b, parsed); err != ErrPatchMismatch {
+		t.Fatalf("Unexpected error.\nExpect:\t%v\nGot:\t%v\n", ErrPatchMismatch, err)
+	}
+	if _, err := ParsePatch("bogus\n"); err == nil {
+		t.Fatal("Expected an error for an invalid patch")
+	}
+}
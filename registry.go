@@ -0,0 +1,116 @@
+package utf32
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+)
+
+// ErrUnknownEncoding is returned by Lookup for labels that are not
+// registered.
+var ErrUnknownEncoding = errors.New("unknown encoding")
+
+// Encoding transcodes between an external encoding and utf-8. *Converter
+// implements it for utf-32.
+type Encoding interface {
+	// Reader returns a reader decoding the bytes read from r to utf-8.
+	Reader(r io.Reader) io.Reader
+	// Writer returns a writer encoding the utf-8 written to it to w.
+	// Close must be called to flush it.
+	Writer(w io.Writer) io.WriteCloser
+}
+
+type registeredEncoding struct {
+	name string
+	enc  Encoding
+}
+
+var (
+	registryMu sync.RWMutex
+	registry   = map[string]registeredEncoding{}
+	// Labels with everything but letters and digits removed, used to
+	// match aliases loosely.
+	looseRegistry = map[string]registeredEncoding{}
+)
+
+func init() {
+	Register("UTF-8", utf8Encoding{},
+		"unicode-1-1-utf-8", "unicode11utf8", "unicode20utf8", "utf8", "x-unicode20utf8", "csUTF8")
+	Register("UTF-32", NewConverter(WithBOM()),
+		"csUTF32", "ucs-4", "csUCS4", "ISO-10646-UCS-4", "ucs4")
+	Register("UTF-32BE", NewConverter(), "csUTF32BE")
+	Register("UTF-32LE", NewConverter(WithByteOrder(binary.LittleEndian)), "csUTF32LE")
+	// As in the WHATWG standard, "utf-16" defaults to little endian.
+	Register("UTF-16", NewConverter(WithUTF16(), WithByteOrder(binary.LittleEndian), WithBOM()),
+		"csUTF16", "unicode", "ucs-2", "csUnicode", "iso-10646-ucs-2", "unicodefeff")
+	Register("UTF-16BE", NewConverter(WithUTF16()), "csUTF16BE", "unicodefffe")
+	Register("UTF-16LE", NewConverter(WithUTF16(), WithByteOrder(binary.LittleEndian)), "csUTF16LE")
+}
+
+// Register makes enc available to Lookup under its canonical name and the
+// given labels, replacing any encoding previously registered with the same
+// name or labels. It is safe to call concurrently with Lookup.
+func Register(name string, enc Encoding, labels ...string) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	e := registeredEncoding{name: name, enc: enc}
+	for _, label := range append([]string{name}, labels...) {
+		registry[normalizeLabel(label)] = e
+		looseRegistry[looseLabel(label)] = e
+	}
+}
+
+// Lookup returns the encoding registered for label and its canonical name.
+// As in the WHATWG Encoding standard, leading and trailing ASCII whitespace
+// is ignored and letters are matched case-insensitively. Labels that still
+// do not match are compared ignoring punctuation, as UTS #22 does for
+// charset aliases, so "UTF32LE" and "utf_32le" find "UTF-32LE".
+func Lookup(label string) (string, Encoding, error) {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	e, ok := registry[normalizeLabel(label)]
+	if !ok {
+		e, ok = looseRegistry[looseLabel(label)]
+	}
+	if !ok {
+		return "", nil, fmt.Errorf("%w: %q", ErrUnknownEncoding, label)
+	}
+	return e.name, e.enc, nil
+}
+
+// normalizeLabel applies the WHATWG label rules: strip ASCII whitespace
+// and lower ASCII letters.
+func normalizeLabel(label string) string {
+	return strings.ToLower(strings.Trim(label, "\t\n\f\r "))
+}
+
+// looseLabel lowers label and drops anything that is not an ASCII letter
+// or digit.
+func looseLabel(label string) string {
+	var sb strings.Builder
+	for i := 0; i < len(label); i++ {
+		switch b := label[i]; {
+		case b >= 'a' && b <= 'z', b >= '0' && b <= '9':
+			sb.WriteByte(b)
+		case b >= 'A' && b <= 'Z':
+			sb.WriteByte(b + 'a' - 'A')
+		}
+	}
+	return sb.String()
+}
+
+// utf8Encoding is the identity Encoding.
+type utf8Encoding struct{}
+
+func (utf8Encoding) Reader(r io.Reader) io.Reader { return r }
+
+func (utf8Encoding) Writer(w io.Writer) io.WriteCloser { return nopWriteCloser{w} }
+
+type nopWriteCloser struct {
+	io.Writer
+}
+
+func (nopWriteCloser) Close() error { return nil }
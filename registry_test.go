@@ -0,0 +1,104 @@
+package utf32
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"maps"
+	"strings"
+	"testing"
+)
+
+func TestLookup(t *testing.T) {
+	var tests = []struct {
+		label string
+		name  string
+	}{
+		{label: "UTF-32BE", name: "UTF-32BE"},
+		{label: "utf-32", name: "UTF-32"},
+		{label: "csUCS4", name: "UTF-32"},
+		{label: "ucs-4", name: "UTF-32"},
+		{label: " Utf-32LE\t", name: "UTF-32LE"},
+		{label: "UTF32LE", name: "UTF-32LE"},
+		{label: "utf8", name: "UTF-8"},
+		{label: "unicode-1-1-utf-8", name: "UTF-8"},
+	}
+	for _, elem := range tests {
+		name, enc, err := Lookup(elem.label)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if expect, got := elem.name, name; expect != got || enc == nil {
+			t.Fatalf("Unexpected encoding for %q.\nExpect:\t%s\nGot:\t%s\n", elem.label, expect, got)
+		}
+	}
+	if _, _, err := Lookup("klingon"); !errors.Is(err, ErrUnknownEncoding) {
+		t.Fatalf("Unexpected error.\nExpect:\t%v\nGot:\t%v\n", ErrUnknownEncoding, err)
+	}
+}
+
+// lowerEncoding decodes by lowercasing.
+type lowerEncoding struct{}
+
+func (lowerEncoding) Reader(r io.Reader) io.Reader {
+	buf, _ := io.ReadAll(r)
+	return strings.NewReader(strings.ToLower(string(buf)))
+}
+
+func (lowerEncoding) Writer(w io.Writer) io.WriteCloser { return nil }
+
+// registerTemp registers enc until the end of the test, restoring the
+// previous registrations then.
+func registerTemp(t *testing.T, name string, enc Encoding, labels ...string) {
+	t.Helper()
+	registryMu.RLock()
+	saved, savedLoose := maps.Clone(registry), maps.Clone(looseRegistry)
+	registryMu.RUnlock()
+	t.Cleanup(func() {
+		registryMu.Lock()
+		defer registryMu.Unlock()
+		registry, looseRegistry = saved, savedLoose
+	})
+	Register(name, enc, labels...)
+}
+
+func TestRegister(t *testing.T) {
+	registerTemp(t, "x-lower", lowerEncoding{}, "whispering")
+	name, enc, err := Lookup("WHISPERING")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expect, got := "x-lower", name; expect != got {
+		t.Fatalf("Unexpected name.\nExpect:\t%s\nGot:\t%s\n", expect, got)
+	}
+	buf, _ := io.ReadAll(enc.Reader(strings.NewReader("HELLO")))
+	if expect, got := "hello", string(buf); expect != got {
+		t.Fatalf("Unexpected result.\nExpect:\t%s\nGot:\t%s\n", expect, got)
+	}
+
+	// Registered codecs round trip.
+	_, enc, err = Lookup("utf-32le")
+	if err != nil {
+		t.Fatal(err)
+	}
+	out := &bytes.Buffer{}
+	w := enc.Writer(out)
+	if _, err := io.WriteString(w, "é"); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if expect, got := []byte{0xe9, 0, 0, 0}, out.Bytes(); !bytes.Equal(expect, got) {
+		t.Fatalf("Unexpected result.\nExpect:\t%x\nGot:\t%x\n", expect, got)
+	}
+}
+
+func TestRegisterTemp(t *testing.T) {
+	t.Run("register", func(t *testing.T) {
+		registerTemp(t, "x-lower", lowerEncoding{}, "whispering")
+	})
+	if _, _, err := Lookup("whispering"); !errors.Is(err, ErrUnknownEncoding) {
+		t.Fatalf("Unexpected error after cleanup.\nExpect:\t%v\nGot:\t%v\n", ErrUnknownEncoding, err)
+	}
+}
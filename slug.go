@@ -0,0 +1,154 @@
+package utf32
+
+import "sort"
+
+// asciiTransliterations maps the lowercase letters that do not decompose to
+// an ASCII letter and a mark to their usual ASCII spelling. It covers Latin,
+// Greek and Cyrillic letters.
+var asciiTransliterations = map[UTF32]string{
+	// Latin.
+	0xdf: "ss", 0xe6: "ae", 0xf0: "d", 0xf8: "o", 0xfe: "th", 0x111: "d", 0x127: "h", 0x131: "i",
+	0x138: "q", 0x142: "l", 0x14b: "ng", 0x153: "oe", 0x167: "t", 0x180: "b", 0x183: "b", 0x188: "c",
+	0x18c: "d", 0x192: "f", 0x199: "k", 0x19a: "l", 0x19e: "n", 0x1a5: "p", 0x1ab: "t", 0x1ad: "t",
+	0x1b4: "y", 0x1b6: "z", 0x1c6: "dz", 0x1c9: "lj", 0x1cc: "nj", 0x1f3: "dz", 0x1e5: "g", 0x23c: "c",
+	0x247: "e", 0x249: "j", 0x24d: "r", 0x24f: "y",
+
+	// Greek, after the tonos and dialytika have been stripped.
+	0x3b1: "a", 0x3b2: "v", 0x3b3: "g", 0x3b4: "d", 0x3b5: "e", 0x3b6: "z", 0x3b7: "i", 0x3b8: "th",
+	0x3b9: "i", 0x3ba: "k", 0x3bb: "l", 0x3bc: "m", 0x3bd: "n", 0x3be: "x", 0x3bf: "o", 0x3c0: "p",
+	0x3c1: "r", 0x3c2: "s", 0x3c3: "s", 0x3c4: "t", 0x3c5: "y", 0x3c6: "f", 0x3c7: "ch", 0x3c8: "ps",
+	0x3c9: "o",
+
+	// Cyrillic, after the breve of й and the diaeresis of ё have been
+	// stripped.
+	0x430: "a", 0x431: "b", 0x432: "v", 0x433: "g", 0x434: "d", 0x435: "e", 0x436: "zh", 0x437: "z",
+	0x438: "i", 0x43a: "k", 0x43b: "l", 0x43c: "m", 0x43d: "n", 0x43e: "o", 0x43f: "p", 0x440: "r",
+	0x441: "s", 0x442: "t", 0x443: "u", 0x444: "f", 0x445: "kh", 0x446: "ts", 0x447: "ch", 0x448: "sh",
+	0x449: "shch", 0x44a: "", 0x44b: "y", 0x44c: "", 0x44d: "e", 0x44e: "yu", 0x44f: "ya", 0x452: "dj",
+	0x454: "ie", 0x455: "dz", 0x456: "i", 0x458: "j", 0x459: "lj", 0x45a: "nj", 0x45b: "c", 0x45f: "dz",
+	0x491: "g",
+}
+
+// Slugger builds URL slugs. Its zero value is not usable; see NewSlugger.
+type Slugger struct {
+	ascii     bool
+	separator UTF32
+	maxLength int
+}
+
+// SlugOption configures a Slugger.
+type SlugOption func(*Slugger)
+
+// SlugASCII makes slugs plain ASCII: letters are stripped of their
+// diacritics, Greek and Cyrillic are transliterated, and other scripts are
+// dropped. By default, letters of all scripts are kept, as IRIs allow.
+func SlugASCII() SlugOption {
+	return func(s *Slugger) { s.ascii = true }
+}
+
+// SlugSeparator sets the code point put between words, '-' by default.
+func SlugSeparator(ch UTF32) SlugOption {
+	return func(s *Slugger) { s.separator = ch }
+}
+
+// SlugMaxLength limits slugs to n code points, cutting them at a grapheme
+// cluster boundary. 0, the default, means no limit.
+func SlugMaxLength(n int) SlugOption {
+	return func(s *Slugger) { s.maxLength = n }
+}
+
+// NewSlugger returns a Slugger configured with opts.
+func NewSlugger(opts ...SlugOption) *Slugger {
+	s := &Slugger{separator: '-'}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// Slug returns the slug of src built with the given options. See
+// Slugger.Slug.
+func Slug(src []UTF32, opts ...SlugOption) []UTF32 {
+	return NewSlugger(opts...).Slug(src)
+}
+
+// Slug returns a slug for src: its words, lowercased and in NFC, joined by
+// the separator. Apostrophes within words are dropped, so "don't" gives
+// "dont", and any other run of code points that are not letters, marks or
+// numbers becomes a single separator. Adjacent words with nothing between
+// them, such as ideographs, are not separated.
+//
+// Word boundaries, lowercasing, normalization and transliteration depend
+// only on the tables of this package, for UnicodeVersion, so a slug does not
+// change with the version of Go. Characters assigned after UnicodeVersion
+// are not letters, marks or numbers and become separators.
+func (s *Slugger) Slug(src []UTF32) []UTF32 {
+	src = NFC(src)
+	ret := make([]UTF32, 0, len(src))
+	pending := false
+	emit := func(ch UTF32) {
+		if pending && len(ret) > 0 {
+			ret = append(ret, s.separator)
+		}
+		pending = false
+		ret = append(ret, ch)
+	}
+	var buf []UTF32
+	for _, word := range Words(src) {
+		if !isWordLike(word) {
+			pending = true
+			continue
+		}
+		for _, ch := range word {
+			if ch == '\'' || ch == 0x2019 {
+				continue
+			}
+			if !inRanges(ch, alphanumeric) {
+				pending = true
+				continue
+			}
+			ch = toLower(ch)
+			if !s.ascii {
+				emit(ch)
+				continue
+			}
+			buf = appendDecomposed(buf[:0], ch)
+			for _, ch := range buf {
+				switch t, ok := asciiTransliterations[ch]; {
+				case ch < 0x80:
+					emit(ch)
+				case ok:
+					for _, ch := range t {
+						emit(UTF32(ch))
+					}
+				case !inRanges(ch, marks):
+					// No ASCII spelling.
+					pending = true
+				}
+			}
+		}
+	}
+	ret = NFC(ret)
+
+	if s.maxLength > 0 && len(ret) > s.maxLength {
+		// Keep marks with their base.
+		n := s.maxLength
+		for n > 0 && inRanges(ret[n], marks) {
+			n--
+		}
+		ret = ret[:n]
+		for len(ret) > 0 && ret[len(ret)-1] == s.separator {
+			ret = ret[:len(ret)-1]
+		}
+	}
+	return ret
+}
+
+// toLower returns the simple lowercase mapping of ch, as of UnicodeVersion.
+func toLower(ch UTF32) UTF32 {
+	i := sort.Search(len(lowercaseMappings), func(i int) bool { return lowercaseMappings[i][0] >= ch })
+	if i < len(lowercaseMappings) && lowercaseMappings[i][0] == ch {
+		return lowercaseMappings[i][1]
+	}
+	return ch
+}
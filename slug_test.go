@@ -0,0 +1,39 @@
+package utf32
+
+import "testing"
+
+func TestSlug(t *testing.T) {
+	var tests = []struct {
+		str    string
+		opts   []SlugOption
+		expect string
+	}{
+		{str: "Hello, World!", expect: "hello-world"},
+		{str: "  --Déjà vu--  ", expect: "déjà-vu"},
+		{str: "Déjà vu", opts: []SlugOption{SlugASCII()}, expect: "deja-vu"},
+		{str: "Don’t stop", expect: "dont-stop"},
+		{str: "Straße & Æsir", opts: []SlugOption{SlugASCII()}, expect: "strasse-aesir"},
+		{str: "Ελληνικά", opts: []SlugOption{SlugASCII()}, expect: "ellinika"},
+		{str: "Привет, мир", opts: []SlugOption{SlugASCII()}, expect: "privet-mir"},
+		{str: "Привет, мир", expect: "привет-мир"},
+		{str: "東京タワー 2024", expect: "東京タワー-2024"},
+		{str: "東京タワー 2024", opts: []SlugOption{SlugASCII()}, expect: "2024"},
+		{str: "Version 3.14", expect: "version-3-14"},
+		{str: "I ❤️ Go", expect: "i-go"},
+		{str: "café", expect: "café"},
+		{str: "foo bar baz", opts: []SlugOption{SlugSeparator('_')}, expect: "foo_bar_baz"},
+		{str: "foo bar baz", opts: []SlugOption{SlugMaxLength(8)}, expect: "foo-bar"},
+		{str: "नमस्ते दुनिया", opts: []SlugOption{SlugMaxLength(5)}, expect: "नमस्"},
+		{str: "!!!", expect: ""},
+		// Characters assigned after UnicodeVersion, a Latin capital with a
+		// lowercase form and a Kawi letter, are separators whatever the
+		// version of Go.
+		{str: "a\ua7cbb", expect: "a-b"},
+		{str: "x\U00011f04y", expect: "x-y"},
+	}
+	for _, elem := range tests {
+		if expect, got := elem.expect, mustString(t, Slug(mustConvert(t, elem.str), elem.opts...)); expect != got {
+			t.Fatalf("Unexpected result for %q.\nExpect:\t%s\nGot:\t%s\n", elem.str, expect, got)
+		}
+	}
+}
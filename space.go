@@ -0,0 +1,116 @@
+package utf32
+
+import "unicode"
+
+// keycap is the combining enclosing keycap, which makes emoji out of digits
+// and '#' or '*' followed by U+FE0F.
+const keycap UTF32 = 0x20e3
+
+// isWhiteSpace reports whether ch has the White_Space property. Unlike
+// unicode.IsSpace, it does not depend on the Latin-1 special case.
+func isWhiteSpace(ch UTF32) bool {
+	return unicode.Is(unicode.White_Space, rune(ch))
+}
+
+// isDefaultIgnorable reports whether ch has the Default_Ignorable_Code_Point
+// property, derived as in DerivedCoreProperties.txt: Other_DICP, Cf and
+// Variation_Selector, minus White_Space, the interlinear annotation and
+// Egyptian hieroglyph format controls and the prepended concatenation
+// marks, which are all visible.
+func isDefaultIgnorable(ch UTF32) bool {
+	r := rune(ch)
+	switch {
+	case ch >= 0xfff9 && ch <= 0xfffb, ch >= 0x13430 && ch <= 0x1343f:
+		return false
+	}
+	return unicode.In(r, unicode.Other_Default_Ignorable_Code_Point, unicode.Cf, unicode.Variation_Selector) &&
+		!unicode.In(r, unicode.White_Space, unicode.Prepended_Concatenation_Mark)
+}
+
+// isEmojiCluster reports whether the grapheme cluster is an emoji whose
+// joiners, variation selectors and tags must be kept.
+func isEmojiCluster(cluster []UTF32) bool {
+	if isExtendedPictographic(cluster[0]) {
+		return true
+	}
+	for _, ch := range cluster {
+		if ch == keycap {
+			return true
+		}
+	}
+	return false
+}
+
+// isSpaceCluster reports whether the grapheme cluster is made only of
+// White_Space code points, such as a space or CR LF. A space carrying a
+// combining mark is not.
+func isSpaceCluster(cluster []UTF32) bool {
+	for _, ch := range cluster {
+		if !isWhiteSpace(ch) {
+			return false
+		}
+	}
+	return true
+}
+
+// TrimSpace returns src without its leading and trailing White_Space, such
+// as no-break or ideographic spaces. Grapheme clusters are removed whole,
+// so a space carrying a combining mark is kept. The returned slice shares
+// the backing array of src.
+//
+// Zero width spaces and byte order marks are not White_Space; use
+// StripIgnorable to remove them.
+func TrimSpace(src []UTF32) []UTF32 {
+	clusters := Graphemes(src)
+	i, j := 0, len(clusters)
+	start, end := 0, len(src)
+	for i < j && isSpaceCluster(clusters[i]) {
+		start += len(clusters[i])
+		i++
+	}
+	for j > i && isSpaceCluster(clusters[j-1]) {
+		j--
+		end -= len(clusters[j])
+	}
+	return src[start:end]
+}
+
+// CollapseSpace returns src trimmed with TrimSpace, and with each inner run
+// of White_Space, line breaks included, replaced by a single U+0020 space.
+func CollapseSpace(src []UTF32) []UTF32 {
+	ret := make([]UTF32, 0, len(src))
+	pending := false
+	for _, cluster := range Graphemes(src) {
+		if isSpaceCluster(cluster) {
+			pending = len(ret) > 0
+			continue
+		}
+		if pending {
+			ret = append(ret, ' ')
+			pending = false
+		}
+		ret = append(ret, cluster...)
+	}
+	return ret
+}
+
+// StripIgnorable returns src without its Default_Ignorable_Code_Point code
+// points, such as zero width spaces, byte order marks, soft hyphens and
+// bidirectional controls. Emoji are kept intact: the zero width joiners,
+// variation selectors and tags of clusters starting with an
+// Extended_Pictographic code point, or forming a keycap, are not removed.
+func StripIgnorable(src []UTF32) []UTF32 {
+	ret := make([]UTF32, 0, len(src))
+	for _, cluster := range Graphemes(src) {
+		if isEmojiCluster(cluster) {
+			ret = append(ret, cluster...)
+			continue
+		}
+		for _, ch := range cluster {
+			if !isDefaultIgnorable(ch) {
+				ret = append(ret, ch)
+			}
+		}
+	}
+	return ret
+}
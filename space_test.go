@@ -0,0 +1,66 @@
+package utf32
+
+import "testing"
+
+func TestTrimSpace(t *testing.T) {
+	var tests = []struct {
+		str    string
+		expect string
+	}{
+		{str: "", expect: ""},
+		{str: "   ", expect: ""},
+		{str: " \t\r\nabc\r\n", expect: "abc"},
+		{str: " \u3000名前\u3000 ", expect: "名前"},
+		{str: "\u0085a b ", expect: "a b"},
+		{str: "\u200babc\ufeff", expect: "\u200babc\ufeff"},
+		{str: "abc \u0301", expect: "abc \u0301"},
+	}
+	for _, elem := range tests {
+		if expect, got := elem.expect, mustString(t, TrimSpace(mustConvert(t, elem.str))); expect != got {
+			t.Fatalf("Unexpected result for %q.\nExpect:\t%q\nGot:\t%q\n", elem.str, expect, got)
+		}
+	}
+}
+
+func TestCollapseSpace(t *testing.T) {
+	var tests = []struct {
+		str    string
+		expect string
+	}{
+		{str: "", expect: ""},
+		{str: "  a   b  ", expect: "a b"},
+		{str: "a  b\r\n\r\nc", expect: "a b c"},
+		{str: "山田\u3000太郎", expect: "山田 太郎"},
+		{str: "👩\u200d💻  dev", expect: "👩\u200d💻 dev"},
+	}
+	for _, elem := range tests {
+		if expect, got := elem.expect, mustString(t, CollapseSpace(mustConvert(t, elem.str))); expect != got {
+			t.Fatalf("Unexpected result for %q.\nExpect:\t%q\nGot:\t%q\n", elem.str, expect, got)
+		}
+	}
+}
+
+func TestStripIgnorable(t *testing.T) {
+	var tests = []struct {
+		str    string
+		expect string
+	}{
+		{str: "\ufeffname\u200b", expect: "name"},
+		{str: "soft\u00adhyphen", expect: "softhyphen"},
+		{str: "\u202eevil\u202c", expect: "evil"},
+		{str: "a\u2060b\u180ec", expect: "abc"},
+		{str: "\u0600123", expect: "\u0600123"},
+		// Emoji ZWJ sequences, presentation selectors, tag sequences and
+		// keycaps survive.
+		{str: "👨\u200d👩\u200d👧 family", expect: "👨\u200d👩\u200d👧 family"},
+		{str: "❤\ufe0f", expect: "❤\ufe0f"},
+		{str: "🏴\U000e0067\U000e0062\U000e0073\U000e0063\U000e0074\U000e007f", expect: "🏴\U000e0067\U000e0062\U000e0073\U000e0063\U000e0074\U000e007f"},
+		{str: "1\ufe0f\u20e3", expect: "1\ufe0f\u20e3"},
+		{str: "a\ufe0f", expect: "a"},
+	}
+	for _, elem := range tests {
+		if expect, got := elem.expect, mustString(t, StripIgnorable(mustConvert(t, elem.str))); expect != got {
+			t.Fatalf("Unexpected result for %q.\nExpect:\t%q\nGot:\t%q\n", elem.str, expect, got)
+		}
+	}
+}
@@ -0,0 +1,200 @@
+package utf32
+
+import "sort"
+
+// SuffixArray returns the suffix array of text: the start indices of its
+// suffixes in code point order. It is built in linear time with SA-IS, over
+// the code points of text renumbered densely.
+func SuffixArray(text []UTF32) []int {
+	if len(text) == 0 {
+		return []int{}
+	}
+	s, alphabet := denseAlphabet(text)
+	sa := sais(s, len(alphabet)+1)
+	// Drop the sentinel suffix, always first.
+	return sa[1:]
+}
+
+// denseAlphabet returns the sorted distinct code points of text, and text
+// with each code point replaced by its rank in them plus one, followed by
+// the 0 sentinel.
+func denseAlphabet(text []UTF32) ([]int, []UTF32) {
+	alphabet := make([]UTF32, len(text))
+	copy(alphabet, text)
+	sort.Slice(alphabet, func(i, j int) bool { return alphabet[i] < alphabet[j] })
+	k := 0
+	for i, ch := range alphabet {
+		if i == 0 || ch != alphabet[k-1] {
+			alphabet[k] = ch
+			k++
+		}
+	}
+	alphabet = alphabet[:k]
+	s := make([]int, len(text)+1)
+	for i, ch := range text {
+		s[i] = sort.Search(k, func(j int) bool { return alphabet[j] >= ch }) + 1
+	}
+	return s, alphabet
+}
+
+// sais returns the suffix array of s, whose symbols are in [0, k) and whose
+// last symbol is a unique 0 sentinel, following Nong, Zhang and Chan's
+// "Two Efficient Algorithms for Linear Time Suffix Array Construction".
+func sais(s []int, k int) []int {
+	n := len(s)
+	sa := make([]int, n)
+	if n == 1 {
+		return sa
+	}
+
+	// Classify suffixes: S-type if smaller than the next one, else L-type.
+	stype := make([]bool, n)
+	stype[n-1] = true
+	for i := n - 2; i >= 0; i-- {
+		stype[i] = s[i] < s[i+1] || (s[i] == s[i+1] && stype[i+1])
+	}
+	isLMS := func(i int) bool { return i > 0 && stype[i] && !stype[i-1] }
+
+	bkt := make([]int, k)
+	buckets := func(end bool) {
+		clear(bkt)
+		for _, c := range s {
+			bkt[c]++
+		}
+		sum := 0
+		for c, cnt := range bkt {
+			sum += cnt
+			if end {
+				bkt[c] = sum
+			} else {
+				bkt[c] = sum - cnt
+			}
+		}
+	}
+	// induce sorts the L-type then the S-type suffixes from the LMS
+	// suffixes already placed at the end of their buckets.
+	induce := func() {
+		buckets(false)
+		for i := 0; i < n; i++ {
+			if j := sa[i] - 1; j >= 0 && !stype[j] {
+				sa[bkt[s[j]]] = j
+				bkt[s[j]]++
+			}
+		}
+		buckets(true)
+		for i := n - 1; i >= 0; i-- {
+			if j := sa[i] - 1; j >= 0 && stype[j] {
+				bkt[s[j]]--
+				sa[bkt[s[j]]] = j
+			}
+		}
+	}
+
+	// Sort the LMS substrings.
+	for i := range sa {
+		sa[i] = -1
+	}
+	buckets(true)
+	for i := 1; i < n; i++ {
+		if isLMS(i) {
+			bkt[s[i]]--
+			sa[bkt[s[i]]] = i
+		}
+	}
+	induce()
+
+	// Name the LMS substrings by rank, equal substrings getting the same
+	// name, storing the names in the second half of sa.
+	n1 := 0
+	for i := 0; i < n; i++ {
+		if isLMS(sa[i]) {
+			sa[n1] = sa[i]
+			n1++
+		}
+	}
+	for i := n1; i < n; i++ {
+		sa[i] = -1
+	}
+	names, prev := 0, -1
+	for i := 0; i < n1; i++ {
+		pos, diff := sa[i], false
+		for d := 0; ; d++ {
+			if prev < 0 || s[pos+d] != s[prev+d] || stype[pos+d] != stype[prev+d] {
+				diff = true
+				break
+			}
+			if d > 0 && (isLMS(pos+d) || isLMS(prev+d)) {
+				break
+			}
+		}
+		if diff {
+			names++
+			prev = pos
+		}
+		sa[n1+pos/2] = names - 1
+	}
+	s1 := make([]int, 0, n1)
+	for i := n1; i < n; i++ {
+		if sa[i] >= 0 {
+			s1 = append(s1, sa[i])
+		}
+	}
+
+	// Sort the LMS suffixes, recursing if their names are not unique.
+	var sa1 []int
+	if names < n1 {
+		sa1 = sais(s1, names)
+	} else {
+		sa1 = make([]int, n1)
+		for i, name := range s1 {
+			sa1[name] = i
+		}
+	}
+
+	// Induce the suffix array from the sorted LMS suffixes.
+	lms := s1[:0]
+	for i := 1; i < n; i++ {
+		if isLMS(i) {
+			lms = append(lms, i)
+		}
+	}
+	for i := range sa {
+		sa[i] = -1
+	}
+	buckets(true)
+	for i := n1 - 1; i >= 0; i-- {
+		j := lms[sa1[i]]
+		bkt[s[j]]--
+		sa[bkt[s[j]]] = j
+	}
+	induce()
+	return sa
+}
+
+// LCPArray returns the longest common prefix array of text and its suffix
+// array sa: lcp[i] is the length of the common prefix of the suffixes at
+// sa[i-1] and sa[i], and lcp[0] is 0. It uses Kasai's linear algorithm.
+func LCPArray(text []UTF32, sa []int) []int {
+	n := len(text)
+	rank := make([]int, n)
+	for i, pos := range sa {
+		rank[pos] = i
+	}
+	lcp := make([]int, n)
+	h := 0
+	for i := 0; i < n; i++ {
+		if rank[i] == 0 {
+			h = 0
+			continue
+		}
+		j := sa[rank[i]-1]
+		for i+h < n && j+h < n && text[i+h] == text[j+h] {
+			h++
+		}
+		lcp[rank[i]] = h
+		if h > 0 {
+			h--
+		}
+	}
+	return lcp
+}
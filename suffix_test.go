@@ -0,0 +1,55 @@
+package utf32
+
+import (
+	"math/rand"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+// naiveSuffixArray sorts the suffixes of text with Compare.
+func naiveSuffixArray(text []UTF32) []int {
+	sa := make([]int, len(text))
+	for i := range sa {
+		sa[i] = i
+	}
+	sort.Slice(sa, func(i, j int) bool { return Compare(text[sa[i]:], text[sa[j]:]) < 0 })
+	return sa
+}
+
+func TestSuffixArray(t *testing.T) {
+	var tests = []struct {
+		str       string
+		expect    []int
+		expectLCP []int
+	}{
+		{str: "", expect: []int{}, expectLCP: []int{}},
+		{str: "banana", expect: []int{5, 3, 1, 0, 4, 2}, expectLCP: []int{0, 1, 3, 0, 0, 2}},
+		{str: "ばなななば", expect: []int{1, 2, 3, 4, 0}, expectLCP: []int{0, 2, 1, 0, 1}},
+	}
+	for _, elem := range tests {
+		text := mustConvert(t, elem.str)
+		sa := SuffixArray(text)
+		if expect, got := elem.expect, sa; !reflect.DeepEqual(expect, got) {
+			t.Fatalf("Unexpected suffix array for %q.\nExpect:\t%v\nGot:\t%v\n", elem.str, expect, got)
+		}
+		if expect, got := elem.expectLCP, LCPArray(text, sa); !reflect.DeepEqual(expect, got) {
+			t.Fatalf("Unexpected lcp array for %q.\nExpect:\t%v\nGot:\t%v\n", elem.str, expect, got)
+		}
+	}
+}
+
+func TestSuffixArrayRandom(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+	for i := 0; i < 500; i++ {
+		// Small alphabets give the repetitions that make SA-IS recurse.
+		alphabet := []UTF32{'a', 'b', 0xe9, 0x65e5, 0x1f600}[:1+rnd.Intn(5)]
+		text := make([]UTF32, rnd.Intn(200))
+		for j := range text {
+			text[j] = alphabet[rnd.Intn(len(alphabet))]
+		}
+		if expect, got := naiveSuffixArray(text), SuffixArray(text); !reflect.DeepEqual(expect, got) {
+			t.Fatalf("Unexpected suffix array for %v.\nExpect:\t%v\nGot:\t%v\n", text, expect, got)
+		}
+	}
+}
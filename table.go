@@ -0,0 +1,171 @@
+package utf32
+
+import "io"
+
+// Alignment of the cells of a table column.
+type Alignment int
+
+// Alignment values.
+const (
+	AlignLeft Alignment = iota
+	AlignRight
+	AlignCenter
+)
+
+// Box-drawing characters used for table borders.
+const (
+	boxHorizontal  UTF32 = 0x2500 // ─
+	boxVertical    UTF32 = 0x2502 // │
+	boxDownRight   UTF32 = 0x250c // ┌
+	boxDownLeft    UTF32 = 0x2510 // ┐
+	boxUpRight     UTF32 = 0x2514 // └
+	boxUpLeft      UTF32 = 0x2518 // ┘
+	boxVerticalR   UTF32 = 0x251c // ├
+	boxVerticalL   UTF32 = 0x2524 // ┤
+	boxHorizontalD UTF32 = 0x252c // ┬
+	boxHorizontalU UTF32 = 0x2534 // ┴
+	boxCross       UTF32 = 0x253c // ┼
+)
+
+// Table lays out rows of cells in aligned columns. Unlike text/tabwriter,
+// column widths are measured in terminal columns using grapheme clusters
+// and East Asian Width, so CJK and emoji cells line up.
+//
+// The zero value is a borderless table with left-aligned columns.
+type Table struct {
+	// Align holds the alignment of each column. Columns past its end are
+	// left-aligned.
+	Align []Alignment
+	// Padding is the number of spaces on each side of a cell.
+	Padding int
+	// Border draws box-drawing borders around and between the cells.
+	Border bool
+	// Header separates the first row from the others. Only used with Border.
+	Header bool
+
+	rows [][][]UTF32
+}
+
+// AddRow appends a row of cells to the table.
+func (t *Table) AddRow(cells ...[]UTF32) {
+	t.rows = append(t.rows, cells)
+}
+
+// AddStrings appends a row of UTF-8 cells to the table.
+func (t *Table) AddStrings(cells ...string) error {
+	row := make([][]UTF32, 0, len(cells))
+	for _, cell := range cells {
+		c, err := ConvertUTF8toUTF32(cell)
+		if err != nil {
+			return err
+		}
+		row = append(row, c)
+	}
+	t.AddRow(row...)
+	return nil
+}
+
+// WriteTo writes the table to w as UTF-8, one line per row.
+func (t *Table) WriteTo(w io.Writer) (int64, error) {
+	var widths []int
+	for _, row := range t.rows {
+		for i, cell := range row {
+			if i >= len(widths) {
+				widths = append(widths, 0)
+			}
+			if cw := Width(cell); cw > widths[i] {
+				widths[i] = cw
+			}
+		}
+	}
+
+	var lines [][]UTF32
+	if t.Border {
+		lines = append(lines, t.rule(widths, boxDownRight, boxHorizontalD, boxDownLeft))
+	}
+	for i, row := range t.rows {
+		lines = append(lines, t.line(widths, row))
+		if t.Border && t.Header && i == 0 && len(t.rows) > 1 {
+			lines = append(lines, t.rule(widths, boxVerticalR, boxCross, boxVerticalL))
+		}
+	}
+	if t.Border {
+		lines = append(lines, t.rule(widths, boxUpRight, boxHorizontalU, boxUpLeft))
+	}
+
+	var total int64
+	for _, line := range lines {
+		str, err := ConvertUTF32toUTF8(append(line, '\n'))
+		if err != nil {
+			return total, err
+		}
+		n, err := io.WriteString(w, str)
+		total += int64(n)
+		if err != nil {
+			return total, err
+		}
+	}
+	return total, nil
+}
+
+// line renders a row of cells.
+func (t *Table) line(widths []int, row [][]UTF32) []UTF32 {
+	var ret []UTF32
+	if t.Border {
+		ret = append(ret, boxVertical)
+	}
+	for i, w := range widths {
+		var cell []UTF32
+		if i < len(row) {
+			cell = row[i]
+		}
+		if i > 0 {
+			if t.Border {
+				ret = append(ret, boxVertical)
+			} else {
+				ret = append(ret, ' ')
+			}
+		}
+		align := AlignLeft
+		if i < len(t.Align) {
+			align = t.Align[i]
+		}
+		left, right := 0, w-Width(cell)
+		switch align {
+		case AlignRight:
+			left, right = right, 0
+		case AlignCenter:
+			left, right = right/2, right-right/2
+		}
+		ret = appendRepeat(ret, ' ', t.Padding+left)
+		ret = append(ret, cell...)
+		ret = appendRepeat(ret, ' ', right+t.Padding)
+	}
+	if t.Border {
+		return append(ret, boxVertical)
+	}
+	// Borderless tables have no trailing blanks.
+	for len(ret) > 0 && ret[len(ret)-1] == ' ' {
+		ret = ret[:len(ret)-1]
+	}
+	return ret
+}
+
+// rule renders a horizontal border line.
+func (t *Table) rule(widths []int, left, middle, right UTF32) []UTF32 {
+	ret := []UTF32{left}
+	for i, w := range widths {
+		if i > 0 {
+			ret = append(ret, middle)
+		}
+		ret = appendRepeat(ret, boxHorizontal, w+2*t.Padding)
+	}
+	return append(ret, right)
+}
+
+func appendRepeat(dst []UTF32, ch UTF32, n int) []UTF32 {
+	for i := 0; i < n; i++ {
+		dst = append(dst, ch)
+	}
+	return dst
+}
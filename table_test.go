@@ -0,0 +1,50 @@
+package utf32
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestTable(t *testing.T) {
+	var tests = []struct {
+		table  Table
+		expect string
+	}{
+		{
+			table: Table{Padding: 0},
+			expect: "" +
+				"name city\n" +
+				"山田 東京\n" +
+				"Zoë  🇫🇷\n",
+		},
+		{
+			table: Table{Align: []Alignment{AlignRight, AlignCenter}, Padding: 1, Border: true, Header: true},
+			expect: "" +
+				"┌──────┬──────┐\n" +
+				"│ name │ city │\n" +
+				"├──────┼──────┤\n" +
+				"│ 山田 │ 東京 │\n" +
+				"│  Zoë │  🇫🇷  │\n" +
+				"└──────┴──────┘\n",
+		},
+	}
+	for _, elem := range tests {
+		tbl := elem.table
+		for _, row := range [][]string{{"name", "city"}, {"山田", "東京"}, {"Zoë", "🇫🇷"}} {
+			if err := tbl.AddStrings(row...); err != nil {
+				t.Fatal(err)
+			}
+		}
+		buf := &strings.Builder{}
+		n, err := tbl.WriteTo(buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if expect, got := elem.expect, buf.String(); expect != got {
+			t.Fatalf("Unexpected result.\nExpect:\n%s\nGot:\n%s\n", expect, got)
+		}
+		if expect, got := int64(buf.Len()), n; expect != got {
+			t.Fatalf("Unexpected byte count.\nExpect:\t%d\nGot:\t%d\n", expect, got)
+		}
+	}
+}
@@ -0,0 +1,171 @@
+package utf32
+
+import (
+	"strings"
+	"unicode"
+)
+
+// titlecaseMappings holds the titlecase mappings of SpecialCasing.txt that
+// expand to several code points, as of Unicode 14.0.
+var titlecaseMappings = map[UTF32][]UTF32{
+	0x00DF: {0x0053, 0x0073}, 0x0149: {0x02BC, 0x004E}, 0x01F0: {0x004A, 0x030C},
+	0x0390: {0x0399, 0x0308, 0x0301}, 0x03B0: {0x03A5, 0x0308, 0x0301}, 0x0587: {0x0535, 0x0582},
+	0x1E96: {0x0048, 0x0331}, 0x1E97: {0x0054, 0x0308}, 0x1E98: {0x0057, 0x030A},
+	0x1E99: {0x0059, 0x030A}, 0x1E9A: {0x0041, 0x02BE}, 0x1F50: {0x03A5, 0x0313},
+	0x1F52: {0x03A5, 0x0313, 0x0300}, 0x1F54: {0x03A5, 0x0313, 0x0301}, 0x1F56: {0x03A5, 0x0313, 0x0342},
+	0x1FB2: {0x1FBA, 0x0345}, 0x1FB4: {0x0386, 0x0345}, 0x1FB6: {0x0391, 0x0342},
+	0x1FB7: {0x0391, 0x0342, 0x0345}, 0x1FC2: {0x1FCA, 0x0345}, 0x1FC4: {0x0389, 0x0345},
+	0x1FC6: {0x0397, 0x0342}, 0x1FC7: {0x0397, 0x0342, 0x0345}, 0x1FD2: {0x0399, 0x0308, 0x0300},
+	0x1FD3: {0x0399, 0x0308, 0x0301}, 0x1FD6: {0x0399, 0x0342}, 0x1FD7: {0x0399, 0x0308, 0x0342},
+	0x1FE2: {0x03A5, 0x0308, 0x0300}, 0x1FE3: {0x03A5, 0x0308, 0x0301}, 0x1FE4: {0x03A1, 0x0313},
+	0x1FE6: {0x03A5, 0x0342}, 0x1FE7: {0x03A5, 0x0308, 0x0342}, 0x1FF2: {0x1FFA, 0x0345},
+	0x1FF4: {0x038F, 0x0345}, 0x1FF6: {0x03A9, 0x0342}, 0x1FF7: {0x03A9, 0x0342, 0x0345},
+	0xFB00: {0x0046, 0x0066}, 0xFB01: {0x0046, 0x0069}, 0xFB02: {0x0046, 0x006C},
+	0xFB03: {0x0046, 0x0066, 0x0069}, 0xFB04: {0x0046, 0x0066, 0x006C}, 0xFB05: {0x0053, 0x0074},
+	0xFB06: {0x0053, 0x0074}, 0xFB13: {0x0544, 0x0576}, 0xFB14: {0x0544, 0x0565},
+	0xFB15: {0x0544, 0x056B}, 0xFB16: {0x054E, 0x0576}, 0xFB17: {0x0544, 0x056D},
+}
+
+// Apostrophes that may join a one letter prefix to a name, as in "o'neill".
+const (
+	apostrophe     UTF32 = '\''
+	rightQuotation UTF32 = 0x2019
+)
+
+// One letter prefixes of names such as "O'Neill", "D'Artagnan" or
+// "L'Oréal". Contractions such as "I'll" or "y'all" are left out.
+var apostrophePrefixes = map[UTF32]bool{'o': true, 'd': true, 'l': true}
+
+// Greek sigmas, lowercased depending on their position in the word.
+const (
+	capitalSigma    UTF32 = 0x3a3
+	finalSmallSigma UTF32 = 0x3c2
+)
+
+// TitleCaser converts text to title case.
+type TitleCaser struct {
+	lang    string
+	special unicode.SpecialCase
+	small   map[string]bool
+}
+
+// TitleOption configures a TitleCaser.
+type TitleOption func(*TitleCaser)
+
+// TitleSmallWords sets words, such as "of" or "the", to lowercase unless
+// they start or end the text. Words are matched regardless of case.
+func TitleSmallWords(words ...string) TitleOption {
+	return func(c *TitleCaser) {
+		for _, w := range words {
+			src, err := ConvertUTF8toUTF32(w)
+			if err != nil {
+				continue
+			}
+			c.small[string(clusterKey(c.lowerWord(nil, src)))] = true
+		}
+	}
+}
+
+// NewTitleCaser returns a TitleCaser for the language with the given BCP 47
+// tag, such as "en" or "nl-BE". Only the primary language subtag is used.
+func NewTitleCaser(lang string, opts ...TitleOption) *TitleCaser {
+	lang = strings.ToLower(lang)
+	if i := strings.IndexAny(lang, "-_"); i >= 0 {
+		lang = lang[:i]
+	}
+	c := &TitleCaser{lang: lang, small: map[string]bool{}}
+	if lang == "tr" || lang == "az" {
+		c.special = unicode.TurkishCase
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// TitleCase returns src in title case for the language lang. See
+// TitleCaser.TitleCase.
+func TitleCase(src []UTF32, lang string, opts ...TitleOption) []UTF32 {
+	return NewTitleCaser(lang, opts...).TitleCase(src)
+}
+
+// TitleCase returns src with the first letter of each word, as found by
+// Words, mapped to title case and the others to lowercase. Digraphs get
+// their own title case form, so "ǆ" gives "ǅ", and letters such as "ß"
+// with a multiple code point mapping give "Ss".
+//
+// A word made of an "o", "d" or "l" prefix, an apostrophe and at least
+// two letters gets two capitals, as in "O'Neill", "D'Artagnan" or
+// "L'Oréal", while contractions such as "don't", "I'll" or "y'all" keep
+// a single one. In Dutch, a leading "ij" gives "IJ", and in Turkish and
+// Azerbaijani, "i" gives "İ".
+func (c *TitleCaser) TitleCase(src []UTF32) []UTF32 {
+	words := Words(src)
+	first, last := -1, -1
+	for i, w := range words {
+		if isWordLike(w) {
+			if first < 0 {
+				first = i
+			}
+			last = i
+		}
+	}
+	ret := make([]UTF32, 0, len(src))
+	for i, w := range words {
+		if !isWordLike(w) {
+			ret = append(ret, w...)
+			continue
+		}
+		start := len(ret)
+		ret = c.lowerWord(ret, w)
+		lower := ret[start:]
+		if i != first && i != last && c.small[string(clusterKey(lower))] {
+			continue
+		}
+		if !unicode.IsLetter(rune(w[0])) {
+			continue
+		}
+		if c.lang == "nl" && len(lower) >= 2 && lower[0] == 'i' && lower[1] == 'j' {
+			lower[0], lower[1] = 'I', 'J'
+			continue
+		}
+		// Title case the letter following a name prefix and an
+		// apostrophe, before the first letter may expand.
+		if len(w) >= 4 && apostrophePrefixes[lower[0]] && (w[1] == apostrophe || w[1] == rightQuotation) &&
+			unicode.IsLetter(rune(w[2])) && unicode.IsLetter(rune(w[3])) {
+			lower[2] = c.toTitle(lower[2])
+		}
+		if t, ok := titlecaseMappings[lower[0]]; ok && c.special == nil {
+			rest := append([]UTF32(nil), ret[start+1:]...)
+			ret = append(append(ret[:start], t...), rest...)
+		} else {
+			lower[0] = c.toTitle(lower[0])
+		}
+	}
+	return ret
+}
+
+func (c *TitleCaser) toTitle(ch UTF32) UTF32 {
+	if c.special != nil {
+		return UTF32(c.special.ToTitle(rune(ch)))
+	}
+	return UTF32(unicode.ToTitle(rune(ch)))
+}
+
+// lowerWord appends the lowercase form of word to dst. A capital sigma
+// ending a word after a letter becomes a final sigma.
+func (c *TitleCaser) lowerWord(dst, word []UTF32) []UTF32 {
+	for i, ch := range word {
+		switch {
+		case ch == capitalSigma && i > 0 && unicode.IsLetter(rune(word[i-1])) &&
+			(i+1 == len(word) || !unicode.IsLetter(rune(word[i+1]))):
+			ch = finalSmallSigma
+		case c.special != nil:
+			ch = UTF32(c.special.ToLower(rune(ch)))
+		default:
+			ch = UTF32(unicode.ToLower(rune(ch)))
+		}
+		dst = append(dst, ch)
+	}
+	return dst
+}
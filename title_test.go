@@ -0,0 +1,40 @@
+package utf32
+
+import "testing"
+
+func TestTitleCase(t *testing.T) {
+	small := TitleSmallWords("a", "of", "the", "and")
+	var tests = []struct {
+		str    string
+		lang   string
+		opts   []TitleOption
+		expect string
+	}{
+		{str: "hello world", lang: "en", expect: "Hello World"},
+		{str: "HELLO wORLD", lang: "en", expect: "Hello World"},
+		{str: "ǆungla", lang: "hr", expect: "ǅungla"},
+		{str: "o'neill and d’artagnan", lang: "en", expect: "O'Neill And D’Artagnan"},
+		{str: "don't stop", lang: "en", expect: "Don't Stop"},
+		{str: "i'll be there", lang: "en", expect: "I'll Be There"},
+		{str: "i've seen y'all", lang: "en", expect: "I've Seen Y'all"},
+		{str: "l'oréal", lang: "fr", expect: "L'Oréal"},
+		{str: "jean-luc picard", lang: "fr", expect: "Jean-Luc Picard"},
+		{str: "ijsselmeer en ijmuiden", lang: "nl", expect: "IJsselmeer En IJmuiden"},
+		{str: "ijsselmeer", lang: "nl-BE", expect: "IJsselmeer"},
+		{str: "ijsselmeer", lang: "en", expect: "Ijsselmeer"},
+		{str: "istanbul izmir", lang: "tr", expect: "İstanbul İzmir"},
+		{str: "ISPARTA", lang: "tr", expect: "Isparta"},
+		{str: "straße ßtest", lang: "de", expect: "Straße Sstest"},
+		{str: "ΟΔΟΣ ΣΟΦΙΑΣ", lang: "el", expect: "Οδος Σοφιας"},
+		{str: "the lord of the rings", lang: "en", opts: []TitleOption{small}, expect: "The Lord of the Rings"},
+		{str: "a tale of two cities: the end", lang: "en", opts: []TitleOption{small}, expect: "A Tale of Two Cities: the End"},
+		{str: "of mice and men", lang: "en", opts: []TitleOption{small}, expect: "Of Mice and Men"},
+		{str: "3rd time, 東京 café", lang: "en", expect: "3rd Time, 東京 Café"},
+		{str: "", lang: "en", expect: ""},
+	}
+	for _, elem := range tests {
+		if expect, got := elem.expect, mustString(t, TitleCase(mustConvert(t, elem.str), elem.lang, elem.opts...)); expect != got {
+			t.Fatalf("Unexpected result for %q.\nExpect:\t%s\nGot:\t%s\n", elem.str, expect, got)
+		}
+	}
+}
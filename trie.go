@@ -0,0 +1,347 @@
+package utf32
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"io"
+	"sort"
+)
+
+// ErrInvalidTrie is returned by Trie.ReadFrom for data not written by
+// Trie.WriteTo.
+var ErrInvalidTrie = errors.New("invalid trie data")
+
+// trieMagic starts the serialized form of a Trie, followed by its version.
+const trieMagic = "utf32trie\x01"
+
+// Trie maps []UTF32 keys to values with prefix search. It is a radix tree:
+// chains of nodes with a single child are merged into one edge labeled with
+// their code points, so memory grows with the number of keys rather than
+// their total length.
+//
+// Keys are folded according to the Trie folding, both when inserted and
+// searched. A Trie is not safe for concurrent writes.
+type Trie struct {
+	folding Folding
+	root    trieNode
+	size    int
+}
+
+type trieNode struct {
+	label    []UTF32
+	children []*trieNode // Sorted by the first code point of their label.
+	terminal bool
+	value    uint64
+}
+
+// NewTrie returns an empty Trie folding its keys with folding.
+func NewTrie(folding Folding) *Trie {
+	return &Trie{folding: folding}
+}
+
+// Folding returns the folding applied to the keys of t.
+func (t *Trie) Folding() Folding { return t.folding }
+
+// Len returns the number of keys in t.
+func (t *Trie) Len() int { return t.size }
+
+// child returns the index of the child of n whose label starts with ch, or
+// where to insert it.
+func (n *trieNode) child(ch UTF32) (int, bool) {
+	i := sort.Search(len(n.children), func(i int) bool { return n.children[i].label[0] >= ch })
+	return i, i < len(n.children) && n.children[i].label[0] == ch
+}
+
+// commonPrefix returns the length of the common prefix of a and b.
+func commonPrefix(a, b []UTF32) int {
+	i := 0
+	for i < len(a) && i < len(b) && a[i] == b[i] {
+		i++
+	}
+	return i
+}
+
+// Insert sets the value of key, replacing any previous value.
+func (t *Trie) Insert(key []UTF32, value uint64) {
+	key = t.folding.Apply(key)
+	n := &t.root
+	for len(key) > 0 {
+		i, ok := n.child(key[0])
+		if !ok {
+			n.children = append(n.children, nil)
+			copy(n.children[i+1:], n.children[i:])
+			n.children[i] = &trieNode{label: key}
+			n = n.children[i]
+			break
+		}
+		c := n.children[i]
+		l := commonPrefix(c.label, key)
+		if l < len(c.label) {
+			// Split the edge where key leaves it.
+			split := &trieNode{label: c.label[:l:l], children: []*trieNode{c}}
+			c.label = c.label[l:]
+			n.children[i] = split
+			c = split
+		}
+		n, key = c, key[l:]
+	}
+	if !n.terminal {
+		t.size++
+	}
+	n.terminal, n.value = true, value
+}
+
+// find returns the node holding the folded key, and how much of the label
+// of the last node was not consumed by it.
+func (t *Trie) find(key []UTF32) (*trieNode, int) {
+	n := &t.root
+	for len(key) > 0 {
+		i, ok := n.child(key[0])
+		if !ok {
+			return nil, 0
+		}
+		n = n.children[i]
+		l := commonPrefix(n.label, key)
+		if l < len(n.label) {
+			if l < len(key) {
+				return nil, 0
+			}
+			return n, len(n.label) - l
+		}
+		key = key[l:]
+	}
+	return n, 0
+}
+
+// Get returns the value of key.
+func (t *Trie) Get(key []UTF32) (uint64, bool) {
+	n, rest := t.find(t.folding.Apply(key))
+	if n == nil || rest != 0 || !n.terminal {
+		return 0, false
+	}
+	return n.value, true
+}
+
+// LongestPrefix returns the length, in code points of src, of the longest
+// key of t that is a prefix of src, and its value.
+func (t *Trie) LongestPrefix(src []UTF32) (int, uint64, bool) {
+	// Record which lengths of the folded text end a code point of src.
+	key := make([]UTF32, 0, len(src))
+	srcLen := make([]int, 1, len(src)+1)
+	for i, ch := range src {
+		key = t.folding.appendFolded(key, ch)
+		for len(srcLen) < len(key) {
+			srcLen = append(srcLen, -1)
+		}
+		if len(srcLen) == len(key) {
+			srcLen = append(srcLen, i+1)
+		} else {
+			// ch folded to nothing: it belongs to the prefix ending
+			// before it.
+			srcLen[len(key)] = i + 1
+		}
+	}
+
+	var (
+		n        = &t.root
+		off      = 0
+		bestLen  = -1
+		bestNode *trieNode
+	)
+	for {
+		if n.terminal && srcLen[off] >= 0 {
+			bestLen, bestNode = srcLen[off], n
+		}
+		if off == len(key) {
+			break
+		}
+		i, ok := n.child(key[off])
+		if !ok {
+			break
+		}
+		n = n.children[i]
+		if commonPrefix(n.label, key[off:]) < len(n.label) {
+			break
+		}
+		off += len(n.label)
+	}
+	if bestNode == nil {
+		return 0, 0, false
+	}
+	return bestLen, bestNode.value, true
+}
+
+// WalkPrefix calls fn for each key of t starting with prefix, in code point
+// order, until fn returns false. Keys are given folded and fn must not
+// retain them.
+func (t *Trie) WalkPrefix(prefix []UTF32, fn func(key []UTF32, value uint64) bool) {
+	prefix = t.folding.Apply(prefix)
+	n, rest := t.find(prefix)
+	if n == nil {
+		return
+	}
+	key := append(prefix[:len(prefix):len(prefix)], n.label[len(n.label)-rest:]...)
+	n.walk(key, fn)
+}
+
+// walk calls fn for n and its descendants, key being the key of n.
+func (n *trieNode) walk(key []UTF32, fn func([]UTF32, uint64) bool) bool {
+	if n.terminal && !fn(key, n.value) {
+		return false
+	}
+	for _, c := range n.children {
+		if !c.walk(append(key, c.label...), fn) {
+			return false
+		}
+	}
+	return true
+}
+
+// WriteTo writes t to w in a compact binary form read by ReadFrom.
+func (t *Trie) WriteTo(w io.Writer) (int64, error) {
+	bw := bufio.NewWriter(w)
+	buf := append([]byte(trieMagic), byte(t.folding))
+	buf = binary.AppendUvarint(buf, uint64(t.size))
+	if _, err := bw.Write(buf); err != nil {
+		return 0, err
+	}
+	n := int64(len(buf))
+	var err error
+	var walk func(*trieNode)
+	walk = func(node *trieNode) {
+		buf = binary.AppendUvarint(buf[:0], uint64(len(node.label)))
+		for _, ch := range node.label {
+			buf = binary.AppendUvarint(buf, uint64(ch))
+		}
+		if node.terminal {
+			buf = append(buf, 1)
+			buf = binary.AppendUvarint(buf, node.value)
+		} else {
+			buf = append(buf, 0)
+		}
+		buf = binary.AppendUvarint(buf, uint64(len(node.children)))
+		if err != nil {
+			return
+		}
+		var m int
+		m, err = bw.Write(buf)
+		n += int64(m)
+		for _, c := range node.children {
+			walk(c)
+		}
+	}
+	walk(&t.root)
+	if err != nil {
+		return n, err
+	}
+	return n, bw.Flush()
+}
+
+// ReadFrom replaces the content of t with the trie read from r, as written
+// by WriteTo. If r does not implement io.ByteReader, it may be read past the
+// end of the trie.
+func (t *Trie) ReadFrom(r io.Reader) (int64, error) {
+	br, ok := r.(io.ByteReader)
+	if !ok {
+		br = bufio.NewReader(r)
+	}
+	cr := &countingByteReader{r: br}
+	magic := make([]byte, len(trieMagic)+1)
+	for i := range magic {
+		b, err := cr.ReadByte()
+		if err != nil {
+			return cr.n, trieReadError(err)
+		}
+		magic[i] = b
+	}
+	if string(magic[:len(trieMagic)]) != trieMagic {
+		return cr.n, ErrInvalidTrie
+	}
+	size, err := binary.ReadUvarint(cr)
+	if err != nil {
+		return cr.n, trieReadError(err)
+	}
+	var root trieNode
+	count := 0
+	if err := root.read(cr, true, &count); err != nil {
+		return cr.n, err
+	}
+	if uint64(count) != size {
+		return cr.n, ErrInvalidTrie
+	}
+	t.folding, t.root, t.size = Folding(magic[len(trieMagic)]), root, count
+	return cr.n, nil
+}
+
+// read reads n and its descendants, counting the keys in count.
+func (n *trieNode) read(r io.ByteReader, root bool, count *int) error {
+	l, err := binary.ReadUvarint(r)
+	if err != nil {
+		return trieReadError(err)
+	}
+	if (l == 0) != root || l > uint64(1<<31) {
+		return ErrInvalidTrie
+	}
+	for ; l > 0; l-- {
+		ch, err := binary.ReadUvarint(r)
+		if err != nil {
+			return trieReadError(err)
+		}
+		if ch > 0xffffffff {
+			return ErrInvalidTrie
+		}
+		n.label = append(n.label, UTF32(ch))
+	}
+	flag, err := r.ReadByte()
+	if err != nil {
+		return trieReadError(err)
+	}
+	switch flag {
+	case 0:
+	case 1:
+		n.terminal = true
+		*count++
+		if n.value, err = binary.ReadUvarint(r); err != nil {
+			return trieReadError(err)
+		}
+	default:
+		return ErrInvalidTrie
+	}
+	children, err := binary.ReadUvarint(r)
+	if err != nil {
+		return trieReadError(err)
+	}
+	for ; children > 0; children-- {
+		c := &trieNode{}
+		if err := c.read(r, false, count); err != nil {
+			return err
+		}
+		if k := len(n.children); k > 0 && n.children[k-1].label[0] >= c.label[0] {
+			return ErrInvalidTrie
+		}
+		n.children = append(n.children, c)
+	}
+	return nil
+}
+
+// trieReadError reports a truncated trie as ErrInvalidTrie.
+func trieReadError(err error) error {
+	if err == io.EOF || err == io.ErrUnexpectedEOF {
+		return ErrInvalidTrie
+	}
+	return err
+}
+
+type countingByteReader struct {
+	r io.ByteReader
+	n int64
+}
+
+func (cr *countingByteReader) ReadByte() (byte, error) {
+	b, err := cr.r.ReadByte()
+	if err == nil {
+		cr.n++
+	}
+	return b, err
+}
@@ -0,0 +1,150 @@
+package utf32
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func newTestTrie(t *testing.T, folding Folding, keys ...string) *Trie {
+	t.Helper()
+	trie := NewTrie(folding)
+	for i, key := range keys {
+		trie.Insert(mustConvert(t, key), uint64(i))
+	}
+	return trie
+}
+
+func walkPrefix(t *testing.T, trie *Trie, prefix string) string {
+	t.Helper()
+	var keys []string
+	trie.WalkPrefix(mustConvert(t, prefix), func(key []UTF32, value uint64) bool {
+		keys = append(keys, mustString(t, key))
+		return true
+	})
+	return strings.Join(keys, ",")
+}
+
+func TestTrieGet(t *testing.T) {
+	trie := newTestTrie(t, 0, "東京", "東京都", "東", "tokyo", "to")
+	trie.Insert(mustConvert(t, "to"), 42)
+	if expect, got := 5, trie.Len(); expect != got {
+		t.Fatalf("Unexpected length.\nExpect:\t%d\nGot:\t%d\n", expect, got)
+	}
+	var tests = []struct {
+		key      string
+		expect   uint64
+		expectOK bool
+	}{
+		{key: "東京", expect: 0, expectOK: true},
+		{key: "東京都", expect: 1, expectOK: true},
+		{key: "東", expect: 2, expectOK: true},
+		{key: "to", expect: 42, expectOK: true},
+		{key: "tok", expect: 0, expectOK: false},
+		{key: "東京都庁", expect: 0, expectOK: false},
+		{key: "", expect: 0, expectOK: false},
+	}
+	for _, elem := range tests {
+		value, ok := trie.Get(mustConvert(t, elem.key))
+		if expect, got := elem.expect, value; expect != got {
+			t.Fatalf("Unexpected value for %q.\nExpect:\t%d\nGot:\t%d\n", elem.key, expect, got)
+		}
+		if expect, got := elem.expectOK, ok; expect != got {
+			t.Fatalf("Unexpected presence of %q.\nExpect:\t%t\nGot:\t%t\n", elem.key, expect, got)
+		}
+	}
+}
+
+func TestTrieLongestPrefix(t *testing.T) {
+	trie := newTestTrie(t, FoldCase|FoldDiacritics, "cafe", "caf", "naive")
+	var tests = []struct {
+		str      string
+		expectN  int
+		expect   uint64
+		expectOK bool
+	}{
+		{str: "Café au lait", expectN: 4, expect: 0, expectOK: true},
+		// The decomposed é is matched entirely, mark included.
+		{str: "cafe\u0301s", expectN: 5, expect: 0, expectOK: true},
+		{str: "CAFard", expectN: 3, expect: 1, expectOK: true},
+		{str: "naïve", expectN: 5, expect: 2, expectOK: true},
+		{str: "ca", expectN: 0, expect: 0, expectOK: false},
+	}
+	for _, elem := range tests {
+		n, value, ok := trie.LongestPrefix(mustConvert(t, elem.str))
+		if expect, got := elem.expectN, n; expect != got {
+			t.Fatalf("Unexpected prefix length for %q.\nExpect:\t%d\nGot:\t%d\n", elem.str, expect, got)
+		}
+		if expect, got := elem.expect, value; expect != got {
+			t.Fatalf("Unexpected value for %q.\nExpect:\t%d\nGot:\t%d\n", elem.str, expect, got)
+		}
+		if expect, got := elem.expectOK, ok; expect != got {
+			t.Fatalf("Unexpected presence of %q.\nExpect:\t%t\nGot:\t%t\n", elem.str, expect, got)
+		}
+	}
+}
+
+func TestTrieWalkPrefix(t *testing.T) {
+	trie := newTestTrie(t, FoldCase, "Straße", "strand", "stream", "str", "Ärger", "äre")
+	var tests = []struct {
+		prefix string
+		expect string
+	}{
+		{prefix: "STR", expect: "STR,STRAND,STRAßE,STREAM"},
+		{prefix: "stra", expect: "STRAND,STRAßE"},
+		{prefix: "strea", expect: "STREAM"},
+		{prefix: "ä", expect: "ÄRE,ÄRGER"},
+		{prefix: "x", expect: ""},
+	}
+	for _, elem := range tests {
+		if expect, got := elem.expect, walkPrefix(t, trie, elem.prefix); expect != got {
+			t.Fatalf("Unexpected result for %q.\nExpect:\t%s\nGot:\t%s\n", elem.prefix, expect, got)
+		}
+	}
+
+	// Stop early.
+	var keys int
+	trie.WalkPrefix(nil, func([]UTF32, uint64) bool {
+		keys++
+		return keys < 2
+	})
+	if expect, got := 2, keys; expect != got {
+		t.Fatalf("Unexpected result.\nExpect:\t%d\nGot:\t%d\n", expect, got)
+	}
+}
+
+func TestTrieSerialization(t *testing.T) {
+	trie := newTestTrie(t, FoldDiacritics, "", "a", "ab", "abc", "b", "日本", "日本語", "𝄞")
+	buf := bytes.NewBuffer(nil)
+	n, err := trie.WriteTo(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expect, got := int64(buf.Len()), n; expect != got {
+		t.Fatalf("Unexpected written length.\nExpect:\t%d\nGot:\t%d\n", expect, got)
+	}
+	data := buf.Bytes()
+
+	got := NewTrie(0)
+	if n, err := got.ReadFrom(bytes.NewReader(data)); err != nil {
+		t.Fatal(err)
+	} else if expect := int64(len(data)); expect != n {
+		t.Fatalf("Unexpected read length.\nExpect:\t%d\nGot:\t%d\n", expect, n)
+	}
+	if expect, got := trie.Folding(), got.Folding(); expect != got {
+		t.Fatalf("Unexpected folding.\nExpect:\t%d\nGot:\t%d\n", expect, got)
+	}
+	if expect, got := walkPrefix(t, trie, ""), walkPrefix(t, got, ""); expect != got {
+		t.Fatalf("Unexpected keys.\nExpect:\t%s\nGot:\t%s\n", expect, got)
+	}
+	if value, ok := got.Get(mustConvert(t, "日本語")); !ok || value != 6 {
+		t.Fatalf("Unexpected value.\nExpect:\t6\nGot:\t%d\n", value)
+	}
+
+	for i := 0; i < len(data); i++ {
+		if _, err := NewTrie(0).ReadFrom(bytes.NewReader(data[:i])); !errors.Is(err, ErrInvalidTrie) {
+			t.Fatalf("Unexpected error for %d bytes.\nExpect:\t%s\nGot:\t%v\n", i, ErrInvalidTrie, err)
+		}
+	}
+}
@@ -0,0 +1,88 @@
+package utf32
+
+// Truncate shortens src so that, followed by tail, it fits in maxWidth
+// terminal columns. Grapheme clusters are never split. If src already fits,
+// it is returned as-is. If tail alone does not fit, it is omitted.
+func Truncate(src []UTF32, maxWidth int, tail []UTF32) []UTF32 {
+	budget, tail, ok := truncateBudget(src, maxWidth, tail)
+	if ok {
+		return src
+	}
+	head := src[:graphemesFromStart(src, budget)]
+	ret := make([]UTF32, 0, len(head)+len(tail))
+	return append(append(ret, head...), tail...)
+}
+
+// TruncateStart is like Truncate but removes code points from the start of
+// src, placing tail in front of what is kept.
+func TruncateStart(src []UTF32, maxWidth int, tail []UTF32) []UTF32 {
+	budget, tail, ok := truncateBudget(src, maxWidth, tail)
+	if ok {
+		return src
+	}
+	end := src[graphemesFromEnd(src, budget):]
+	ret := make([]UTF32, 0, len(tail)+len(end))
+	return append(append(ret, tail...), end...)
+}
+
+// TruncateMiddle is like Truncate but removes code points from the middle
+// of src, placing tail between the kept start and end. The start gets the
+// first half of the available width and the end whatever the start left.
+func TruncateMiddle(src []UTF32, maxWidth int, tail []UTF32) []UTF32 {
+	budget, tail, ok := truncateBudget(src, maxWidth, tail)
+	if ok {
+		return src
+	}
+	head := src[:graphemesFromStart(src, budget-budget/2)]
+	end := src[len(head)+graphemesFromEnd(src[len(head):], budget-Width(head)):]
+	ret := make([]UTF32, 0, len(head)+len(tail)+len(end))
+	return append(append(append(ret, head...), tail...), end...)
+}
+
+// truncateBudget returns the width available for src once tail is placed,
+// and the tail to use. ok is true if src does not need truncating.
+func truncateBudget(src []UTF32, maxWidth int, tail []UTF32) (int, []UTF32, bool) {
+	if maxWidth < 0 {
+		maxWidth = 0
+	}
+	if Width(src) <= maxWidth {
+		return maxWidth, tail, true
+	}
+	tw := Width(tail)
+	if tw > maxWidth {
+		return maxWidth, nil, false
+	}
+	return maxWidth - tw, tail, false
+}
+
+// graphemesFromStart returns the length of the longest run of whole grapheme
+// clusters at the start of src that fits in width columns.
+func graphemesFromStart(src []UTF32, width int) int {
+	n := 0
+	for n < len(src) {
+		l := FirstGrapheme(src[n:])
+		w := graphemeWidth(src[n : n+l])
+		if w > width {
+			break
+		}
+		width -= w
+		n += l
+	}
+	return n
+}
+
+// graphemesFromEnd returns the index in src where the longest run of whole
+// grapheme clusters at the end of src that fits in width columns starts.
+func graphemesFromEnd(src []UTF32, width int) int {
+	clusters := Graphemes(src)
+	start := len(src)
+	for i := len(clusters) - 1; i >= 0; i-- {
+		w := graphemeWidth(clusters[i])
+		if w > width {
+			break
+		}
+		width -= w
+		start -= len(clusters[i])
+	}
+	return start
+}
@@ -0,0 +1,31 @@
+package utf32
+
+import "testing"
+
+func TestTruncate(t *testing.T) {
+	family := "\U0001F468‍\U0001F469‍\U0001F467"
+	var tests = []struct {
+		fn       func([]UTF32, int, []UTF32) []UTF32
+		str      string
+		maxWidth int
+		tail     string
+		expect   string
+	}{
+		{fn: Truncate, str: "hello", maxWidth: 5, tail: "…", expect: "hello"},
+		{fn: Truncate, str: "hello world", maxWidth: 6, tail: "…", expect: "hello…"},
+		{fn: Truncate, str: "日本語テキスト", maxWidth: 6, tail: "…", expect: "日本…"},
+		{fn: Truncate, str: "ab" + family + "cd", maxWidth: 4, tail: "…", expect: "ab…"},
+		{fn: Truncate, str: "ab" + family + "cd", maxWidth: 5, tail: "…", expect: "ab" + family + "…"},
+		{fn: Truncate, str: "hello", maxWidth: 2, tail: "...", expect: "he"},
+		{fn: TruncateStart, str: "hello world", maxWidth: 6, tail: "…", expect: "…world"},
+		{fn: TruncateStart, str: "ééé", maxWidth: 2, tail: "…", expect: "…é"},
+		{fn: TruncateMiddle, str: "hello world", maxWidth: 6, tail: "…", expect: "hel…ld"},
+		{fn: TruncateMiddle, str: "日本語テキスト", maxWidth: 7, tail: "…", expect: "日…スト"},
+	}
+	for _, elem := range tests {
+		got := mustString(t, elem.fn(mustConvert(t, elem.str), elem.maxWidth, mustConvert(t, elem.tail)))
+		if expect := elem.expect; expect != got {
+			t.Fatalf("Unexpected result for %q.\nExpect:\t%q\nGot:\t%q\n", elem.str, expect, got)
+		}
+	}
+}
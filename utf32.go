@@ -77,68 +77,105 @@ func lookupBytesToWrite(ch UTF32) (int, error) {
 
 // ConvertUTF32toUTF8 converts the given utf32 as a utf8 string.
 func ConvertUTF32toUTF8(src []UTF32) (string, error) {
-	// TODO: improve allocations.
-	ret := make([]byte, 0, len(src)*4)
-	idx := 0
-	for _, ch := range src {
-		// UTF-16 surrogate values are illegal in UTF-32.
-		if ch >= UniSurHighStart && ch <= UniSurLowEnd {
-			return "", ErrInvalidSource
-		}
-
-		// Figure out how many bytes the result will require.
-		bytesToWrite, err := lookupBytesToWrite(ch)
-		if err != nil {
-			return "", err
-		}
+	return defaultConverter.Encode(src)
+}
 
-		// Extend `ret` length
-		for i := 0; i < bytesToWrite; i++ {
-			ret = append(ret, 0)
-		}
-		switch bytesToWrite {
-		case 4:
-			ret[idx+3] = byte((int(ch) | byteMark) & byteMask)
-			ch >>= 6
-			fallthrough
-		case 3:
-			ret[idx+2] = byte((int(ch) | byteMark) & byteMask)
-			ch >>= 6
-			fallthrough
-		case 2:
-			ret[idx+1] = byte((int(ch) | byteMark) & byteMask)
-			ch >>= 6
-			fallthrough
-		case 1:
-			ret[idx] = byte((int(ch) | int(firstByteMark[bytesToWrite])))
-		}
-		idx += bytesToWrite
+// convertUTF32toUTF8 implements ConvertUTF32toUTF8, recording offsets in m
+// if not nil.
+func convertUTF32toUTF8(src []UTF32, m *OffsetMap) (string, error) {
+	// TODO: improve allocations.
+	ret, err := defaultConverter.encode(make([]byte, 0, len(src)*4), src, m)
+	if err != nil {
+		return "", err
 	}
 	return string(ret), nil
 }
 
+// appendUTF8 appends the utf-8 encoding of ch, which is bytesToWrite long,
+// to ret.
+func appendUTF8(ret []byte, ch UTF32, bytesToWrite int) []byte {
+	idx := len(ret)
+	// Extend `ret` length
+	for i := 0; i < bytesToWrite; i++ {
+		ret = append(ret, 0)
+	}
+	switch bytesToWrite {
+	case 4:
+		ret[idx+3] = byte((int(ch) | byteMark) & byteMask)
+		ch >>= 6
+		fallthrough
+	case 3:
+		ret[idx+2] = byte((int(ch) | byteMark) & byteMask)
+		ch >>= 6
+		fallthrough
+	case 2:
+		ret[idx+1] = byte((int(ch) | byteMark) & byteMask)
+		ch >>= 6
+		fallthrough
+	case 1:
+		ret[idx] = byte((int(ch) | int(firstByteMark[bytesToWrite])))
+	}
+	return ret
+}
+
 // ConvertUTF8toUTF32 converts the given utf-8 string to an utf-32 buffer.
 func ConvertUTF8toUTF32(src string) ([]UTF32, error) {
-	ret := []UTF32{}
-	for i := 0; i < len(src); i++ {
-		var extraBytesToRead = trailingBytesForUTF8[src[i]]
+	return defaultConverter.Decode(src)
+}
 
-		if i+int(extraBytesToRead) >= len(src) {
-			return nil, ErrInvalidSource
-		}
+// convertUTF8toUTF32 implements ConvertUTF8toUTF32, recording offsets in m
+// if not nil.
+func convertUTF8toUTF32(src string, m *OffsetMap) ([]UTF32, error) {
+	return defaultConverter.decode([]UTF32{}, src, m)
+}
 
-		var ch UTF32
-		for j := 0; j < int(extraBytesToRead); j++ {
-			ch += UTF32(src[i])
-			i++
-			ch <<= 6
-		}
-		ch += UTF32(src[i]) - offsetsFromUTF8[extraBytesToRead]
+// decodeUTF8Lax decodes the utf-8 sequence starting at src[i] the way the
+// original algorithm does: the length of the sequence is given by its first
+// byte and the following bytes are not checked. It returns ok false if the
+// sequence is truncated, a surrogate or out of range.
+func decodeUTF8Lax(src string, i int) (ch UTF32, size int, ok bool) {
+	var extraBytesToRead = int(trailingBytesForUTF8[src[i]])
+
+	if i+extraBytesToRead >= len(src) {
+		return 0, 0, false
+	}
 
-		if ch > UniMaxLegalUTF32 || (ch >= UniSurHighStart && ch <= UniSurLowEnd) {
-			return nil, ErrInvalidSource
+	for j := 0; j < extraBytesToRead; j++ {
+		ch += UTF32(src[i+j])
+		ch <<= 6
+	}
+	ch += UTF32(src[i+extraBytesToRead]) - offsetsFromUTF8[extraBytesToRead]
+
+	if ch > UniMaxLegalUTF32 || (ch >= UniSurHighStart && ch <= UniSurLowEnd) {
+		return 0, 0, false
+	}
+	return ch, extraBytesToRead + 1, true
+}
+
+// decodeUTF8 strictly decodes the utf-8 sequence starting at src[i]. It
+// returns the code point and the sequence length, or ok false if the
+// sequence is truncated, overlong, a surrogate or out of range.
+func decodeUTF8(src string, i int) (ch UTF32, size int, ok bool) {
+	extraBytesToRead := int(trailingBytesForUTF8[src[i]])
+	if (src[i] >= 0x80 && extraBytesToRead == 0) || extraBytesToRead > 3 || i+extraBytesToRead >= len(src) {
+		return 0, 0, false
+	}
+	for j := 0; j < extraBytesToRead; j++ {
+		ch += UTF32(src[i+j])
+		ch <<= 6
+		if src[i+j+1]&0xc0 != byteMark {
+			return 0, 0, false
 		}
-		ret = append(ret, ch)
 	}
-	return ret, nil
+	ch += UTF32(src[i+extraBytesToRead]) - offsetsFromUTF8[extraBytesToRead]
+
+	// Reject overlong forms, which decode to a value that would fit in
+	// fewer bytes.
+	if n, err := lookupBytesToWrite(ch); err != nil || n != extraBytesToRead+1 {
+		return 0, 0, false
+	}
+	if ch >= UniSurHighStart && ch <= UniSurLowEnd {
+		return 0, 0, false
+	}
+	return ch, extraBytesToRead + 1, true
 }
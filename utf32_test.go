@@ -37,3 +37,21 @@ func TestRoundTrip(t *testing.T) {
 		}
 	}
 }
+
+func mustConvert(t testing.TB, str string) []UTF32 {
+	t.Helper()
+	ret, err := ConvertUTF8toUTF32(str)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return ret
+}
+
+func mustString(t testing.TB, src []UTF32) string {
+	t.Helper()
+	ret, err := ConvertUTF32toUTF8(src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return ret
+}
@@ -0,0 +1,204 @@
+// Package utf32csv reads and writes CSV files in utf-8, utf-16 or utf-32,
+// such as the "Unicode text" files exported by Excel, transcoding them with
+// the encodings registered with utf32.Register.
+package utf32csv
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"io"
+	"unicode/utf8"
+
+	"github.com/creack/utf32"
+)
+
+// Reader reads CSV records from a file in any Unicode encoding. The
+// embedded csv.Reader can be configured as usual, e.g. Comma can be set to
+// any delimiter, including non-ASCII ones.
+//
+// Errors are reported as *csv.ParseError, with the line and byte column in
+// the utf-8 decoding of the file. This includes input that is invalid in
+// its encoding.
+type Reader struct {
+	*csv.Reader
+	// Encoding is the canonical name of the detected encoding.
+	Encoding string
+
+	pos *positionReader
+}
+
+// NewReader returns a Reader detecting the encoding of r. A byte order mark
+// identifies utf-8, utf-16 and utf-32 in either byte order and is stripped.
+// Without one, the pattern of NUL bytes at the start of r is used to tell
+// utf-16 and utf-32 from utf-8.
+func NewReader(r io.Reader) (*Reader, error) {
+	br := bufio.NewReader(r)
+	head, err := br.Peek(64)
+	if err != nil && err != io.EOF && err != bufio.ErrBufferFull {
+		return nil, err
+	}
+	name := detect(head)
+	if name == "UTF-8" && bytes.HasPrefix(head, []byte("\xef\xbb\xbf")) {
+		_, _ = br.Discard(3)
+	}
+	_, enc, err := utf32.Lookup(name)
+	if err != nil {
+		return nil, err
+	}
+	src := enc.Reader(br)
+	if name == "UTF-8" {
+		// Check utf-8, which the encoding passes through, as the others are.
+		src = &utf8Reader{r: br}
+	}
+	pos := &positionReader{r: src, line: 1}
+	return &Reader{Reader: csv.NewReader(pos), Encoding: name, pos: pos}, nil
+}
+
+// detect returns the name of the encoding of a file starting with head.
+func detect(head []byte) string {
+	switch {
+	case bytes.HasPrefix(head, []byte{0xff, 0xfe, 0, 0}), bytes.HasPrefix(head, []byte{0, 0, 0xfe, 0xff}):
+		return "UTF-32"
+	case bytes.HasPrefix(head, []byte{0xff, 0xfe}), bytes.HasPrefix(head, []byte{0xfe, 0xff}):
+		return "UTF-16"
+	case len(head) >= 4 && head[0] != 0 && head[1] == 0 && head[2] == 0 && head[3] == 0:
+		return "UTF-32LE"
+	case len(head) >= 4 && head[0] == 0 && head[1] == 0 && head[2] == 0 && head[3] != 0:
+		return "UTF-32BE"
+	case len(head) >= 2 && head[0] != 0 && head[1] == 0:
+		return "UTF-16LE"
+	case len(head) >= 2 && head[0] == 0 && head[1] != 0:
+		return "UTF-16BE"
+	}
+	return "UTF-8"
+}
+
+// Read reads one record. It wraps csv.Reader.Read to report decoding
+// errors with their position.
+func (r *Reader) Read() ([]string, error) {
+	record, err := r.Reader.Read()
+	return record, r.wrap(err)
+}
+
+// ReadAll reads all the remaining records.
+func (r *Reader) ReadAll() ([][]string, error) {
+	records, err := r.Reader.ReadAll()
+	return records, r.wrap(err)
+}
+
+// ReadUTF32 reads one record and returns its fields as []UTF32.
+func (r *Reader) ReadUTF32() ([][]utf32.UTF32, error) {
+	record, err := r.Read()
+	if err != nil {
+		return nil, err
+	}
+	ret := make([][]utf32.UTF32, 0, len(record))
+	for _, field := range record {
+		f, err := utf32.ConvertUTF8toUTF32(field)
+		if err != nil {
+			return nil, err
+		}
+		ret = append(ret, f)
+	}
+	return ret, nil
+}
+
+// wrap gives decoding errors, returned by the underlying reader as is, the
+// position where the decoding stopped.
+func (r *Reader) wrap(err error) error {
+	var parseErr *csv.ParseError
+	if err == nil || err == io.EOF || errors.As(err, &parseErr) {
+		return err
+	}
+	return &csv.ParseError{StartLine: r.pos.line, Line: r.pos.line, Column: r.pos.column + 1, Err: err}
+}
+
+// utf8Reader passes valid utf-8 through and fails with
+// utf32.ErrInvalidSource at the first invalid sequence.
+type utf8Reader struct {
+	r *bufio.Reader
+}
+
+func (u *utf8Reader) Read(p []byte) (int, error) {
+	if len(p) < utf8.UTFMax {
+		return 0, io.ErrShortBuffer
+	}
+	n := 0
+	// Stop before blocking once some input is returned.
+	for n+utf8.UTFMax <= len(p) && (n == 0 || u.r.Buffered() > 0) {
+		ch, size, err := u.r.ReadRune()
+		switch {
+		case err == io.EOF && n > 0:
+			return n, nil
+		case err != nil:
+			return n, err
+		case ch == utf8.RuneError && size == 1:
+			return n, utf32.ErrInvalidSource
+		}
+		n += utf8.EncodeRune(p[n:], ch)
+	}
+	return n, nil
+}
+
+// positionReader tracks the line and column reached in the utf-8 stream.
+type positionReader struct {
+	r      io.Reader
+	line   int
+	column int
+}
+
+func (p *positionReader) Read(buf []byte) (int, error) {
+	n, err := p.r.Read(buf)
+	for _, b := range buf[:n] {
+		if b == '\n' {
+			p.line++
+			p.column = 0
+		} else {
+			p.column++
+		}
+	}
+	return n, err
+}
+
+// Writer writes CSV records in any registered encoding. The embedded
+// csv.Writer can be configured as usual.
+type Writer struct {
+	*csv.Writer
+	enc io.WriteCloser
+}
+
+// NewWriter returns a Writer encoding records to w in the given charset,
+// e.g. "utf-16" for the utf-16le with byte order mark that Excel expects.
+func NewWriter(w io.Writer, charset string) (*Writer, error) {
+	_, enc, err := utf32.Lookup(charset)
+	if err != nil {
+		return nil, err
+	}
+	ew := enc.Writer(w)
+	return &Writer{Writer: csv.NewWriter(ew), enc: ew}, nil
+}
+
+// WriteUTF32 writes a record whose fields are []UTF32.
+func (w *Writer) WriteUTF32(record [][]utf32.UTF32) error {
+	fields := make([]string, 0, len(record))
+	for _, field := range record {
+		f, err := utf32.ConvertUTF32toUTF8(field)
+		if err != nil {
+			return err
+		}
+		fields = append(fields, f)
+	}
+	return w.Write(fields)
+}
+
+// Close flushes the records and the encoder. It does not close the
+// underlying writer.
+func (w *Writer) Close() error {
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return err
+	}
+	return w.enc.Close()
+}
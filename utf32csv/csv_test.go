@@ -0,0 +1,146 @@
+package utf32csv
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/creack/utf32"
+)
+
+func TestRoundTrip(t *testing.T) {
+	records := [][]string{
+		{"name", "city"},
+		{"山田", "東京"},
+		{"Zoë, \"jr\"", "𒔊"},
+	}
+	for _, charset := range []string{"utf-8", "utf-16", "utf-16le", "utf-16be", "utf-32", "utf-32le", "utf-32be"} {
+		buf := &bytes.Buffer{}
+		w, err := NewWriter(buf, charset)
+		if err != nil {
+			t.Fatal(err)
+		}
+		w.Comma = '→'
+		if err := w.WriteAll(records); err != nil {
+			t.Fatal(err)
+		}
+		if err := w.Close(); err != nil {
+			t.Fatal(err)
+		}
+
+		r, err := NewReader(bytes.NewReader(buf.Bytes()))
+		if err != nil {
+			t.Fatal(err)
+		}
+		r.Comma = '→'
+		got, err := r.ReadAll()
+		if err != nil {
+			t.Fatalf("%s: %s", charset, err)
+		}
+		if len(got) != len(records) {
+			t.Fatalf("Unexpected records for %s.\nExpect:\t%q\nGot:\t%q\n", charset, records, got)
+		}
+		for i := range records {
+			for j := range records[i] {
+				if expect, got := records[i][j], got[i][j]; expect != got {
+					t.Fatalf("Unexpected field for %s.\nExpect:\t%q\nGot:\t%q\n", charset, expect, got)
+				}
+			}
+		}
+	}
+}
+
+func TestDetect(t *testing.T) {
+	var tests = []struct {
+		data   []byte
+		expect string
+	}{
+		{data: []byte("\xef\xbb\xbfa,b"), expect: "UTF-8"},
+		{data: []byte("a,b"), expect: "UTF-8"},
+		{data: []byte{0xff, 0xfe, 'a', 0}, expect: "UTF-16"},
+		{data: []byte{'a', 0, ',', 0}, expect: "UTF-16LE"},
+		{data: []byte{0, 'a', 0, ','}, expect: "UTF-16BE"},
+		{data: []byte{0xff, 0xfe, 0, 0}, expect: "UTF-32"},
+		{data: []byte{'a', 0, 0, 0}, expect: "UTF-32LE"},
+		{data: []byte{0, 0, 0, 'a'}, expect: "UTF-32BE"},
+	}
+	for _, elem := range tests {
+		r, err := NewReader(bytes.NewReader(elem.data))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if expect, got := elem.expect, r.Encoding; expect != got {
+			t.Fatalf("Unexpected encoding for %x.\nExpect:\t%s\nGot:\t%s\n", elem.data, expect, got)
+		}
+	}
+
+	r, err := NewReader(bytes.NewReader([]byte("\xef\xbb\xbfa,b\n")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	record, err := r.ReadUTF32()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(record) != 2 || len(record[0]) != 1 || record[0][0] != 'a' {
+		t.Fatalf("Unexpected record: %U\n", record)
+	}
+}
+
+func TestErrors(t *testing.T) {
+	// A lone surrogate on the second line of a utf-16le file.
+	data := []byte{'a', 0, ',', 0, 'b', 0, '\n', 0, 'c', 0, 0x00, 0xd8, 'd', 0, '\n', 0}
+	r, err := NewReader(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = r.ReadAll()
+	var parseErr *csv.ParseError
+	if !errors.As(err, &parseErr) || !errors.Is(err, utf32.ErrInvalidSource) {
+		t.Fatalf("Unexpected error: %v\n", err)
+	}
+	if expect, got := 2, parseErr.Line; expect != got {
+		t.Fatalf("Unexpected line.\nExpect:\t%d\nGot:\t%d\n", expect, got)
+	}
+	if expect, got := 2, parseErr.Column; expect != got {
+		t.Fatalf("Unexpected column.\nExpect:\t%d\nGot:\t%d\n", expect, got)
+	}
+
+	// Invalid utf-8 is rejected like invalid utf-16 or utf-32, including a
+	// surrogate and a truncated sequence at the end.
+	for _, str := range []string{"a,b\nc\xffd\n", "a,b\nc\xed\xa0\x80\n", "a,b\nc\xe6\x97"} {
+		r, err = NewReader(strings.NewReader(str))
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, err = r.ReadAll()
+		if !errors.As(err, &parseErr) || !errors.Is(err, utf32.ErrInvalidSource) {
+			t.Fatalf("Unexpected error for %q: %v\n", str, err)
+		}
+		if expect, got := 2, parseErr.Line; expect != got {
+			t.Fatalf("Unexpected line for %q.\nExpect:\t%d\nGot:\t%d\n", str, expect, got)
+		}
+		if expect, got := 2, parseErr.Column; expect != got {
+			t.Fatalf("Unexpected column for %q.\nExpect:\t%d\nGot:\t%d\n", str, expect, got)
+		}
+	}
+
+	// An encoded U+FFFD is valid.
+	r, err = NewReader(strings.NewReader("\ufffd,b\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if records, err := r.ReadAll(); err != nil || len(records) != 1 || records[0][0] != "\ufffd" {
+		t.Fatalf("Unexpected result: %q, %v\n", records, err)
+	}
+
+	r, err = NewReader(bytes.NewReader([]byte("a,\"b\n")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := r.ReadAll(); !errors.As(err, &parseErr) || parseErr.Line != 1 {
+		t.Fatalf("Unexpected error: %v\n", err)
+	}
+}
@@ -0,0 +1,213 @@
+// Package utf32http transcodes HTTP bodies between the charsets used by
+// clients and the utf-8 handlers work with, using the encodings registered
+// with utf32.Register.
+package utf32http
+
+import (
+	"io"
+	"log"
+	"mime"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/creack/utf32"
+)
+
+// Middleware wraps next so that it only deals with utf-8:
+//
+//   - A request body whose Content-Type has a charset parameter is decoded
+//     to utf-8 before next is called, and the charset is changed to utf-8.
+//     Unknown charsets are answered with 415 Unsupported Media Type, bodies
+//     that are invalid in their charset with 400 Bad Request.
+//   - A textual response is encoded to the charset the client prefers
+//     according to Accept-Charset, which is set in the Content-Type of the
+//     response. When no acceptable charset is registered, utf-8 is used.
+//
+// Request bodies are decoded as next reads them. If reading fails before
+// next starts its response, 400 Bad Request is sent instead and writes to
+// the response fail with the decoding error. Errors flushing the encoded
+// response are logged.
+func Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		name, enc := negotiate(r.Header.Get("Accept-Charset"))
+		rw := &responseWriter{ResponseWriter: w, name: name, enc: enc}
+		if code := decodeRequest(r, rw); code != 0 {
+			http.Error(w, http.StatusText(code), code)
+			return
+		}
+		next.ServeHTTP(rw, r)
+		if !rw.wroteHeader {
+			rw.WriteHeader(http.StatusOK)
+		}
+		if err := rw.close(); err != nil {
+			log.Printf("utf32http: encoding response to %s: %s", name, err)
+		}
+	})
+}
+
+// decodeRequest replaces the body of r with a reader decoding it to utf-8,
+// which reports read errors to rw. It returns the status code to reply with
+// on failure, 0 otherwise.
+func decodeRequest(r *http.Request, rw *responseWriter) int {
+	mediaType, params, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if err != nil || params["charset"] == "" || r.Body == nil {
+		return 0
+	}
+	name, enc, err := utf32.Lookup(params["charset"])
+	if err != nil {
+		return http.StatusUnsupportedMediaType
+	}
+	if name == "UTF-8" {
+		return 0
+	}
+	params["charset"] = "utf-8"
+	r.Header.Set("Content-Type", mime.FormatMediaType(mediaType, params))
+	r.Header.Del("Content-Length")
+	r.ContentLength = -1
+	r.Body = &requestBody{r: enc.Reader(r.Body), body: r.Body, rw: rw}
+	return 0
+}
+
+// requestBody decodes a request body as it is read.
+type requestBody struct {
+	r    io.Reader
+	body io.ReadCloser
+	rw   *responseWriter
+}
+
+func (b *requestBody) Read(p []byte) (int, error) {
+	n, err := b.r.Read(p)
+	if err != nil && err != io.EOF && b.rw.bodyErr == nil {
+		b.rw.bodyErr = err
+	}
+	return n, err
+}
+
+func (b *requestBody) Close() error {
+	return b.body.Close()
+}
+
+// negotiate returns the registered charset preferred by the Accept-Charset
+// header, or a nil Encoding if utf-8 should be used.
+func negotiate(accept string) (string, utf32.Encoding) {
+	type candidate struct {
+		label string
+		q     float64
+	}
+	var candidates []candidate
+	for _, part := range strings.Split(accept, ",") {
+		label, params, _ := strings.Cut(part, ";")
+		label = strings.TrimSpace(label)
+		if label == "" || label == "*" {
+			continue
+		}
+		q := 1.0
+		for _, param := range strings.Split(params, ";") {
+			k, v, _ := strings.Cut(strings.TrimSpace(param), "=")
+			if k == "q" {
+				if f, err := strconv.ParseFloat(v, 64); err == nil {
+					q = f
+				}
+			}
+		}
+		if q > 0 {
+			candidates = append(candidates, candidate{label: label, q: q})
+		}
+	}
+	sort.SliceStable(candidates, func(i, j int) bool { return candidates[i].q > candidates[j].q })
+	for _, c := range candidates {
+		name, enc, err := utf32.Lookup(c.label)
+		if err != nil {
+			continue
+		}
+		if name == "UTF-8" {
+			return name, nil
+		}
+		return name, enc
+	}
+	return "UTF-8", nil
+}
+
+// responseWriter encodes textual responses, unless enc is nil, and answers
+// with 400 Bad Request once the request body failed to decode.
+type responseWriter struct {
+	http.ResponseWriter
+	name        string
+	enc         utf32.Encoding
+	w           io.WriteCloser
+	wroteHeader bool
+	bodyErr     error
+	rejected    bool
+}
+
+func (rw *responseWriter) WriteHeader(code int) {
+	if rw.wroteHeader {
+		return
+	}
+	rw.wroteHeader = true
+	if rw.bodyErr != nil {
+		rw.rejected = true
+		http.Error(rw.ResponseWriter, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+		return
+	}
+	if rw.enc == nil {
+		rw.ResponseWriter.WriteHeader(code)
+		return
+	}
+	h := rw.Header()
+	mediaType, params, err := mime.ParseMediaType(h.Get("Content-Type"))
+	textual := err == nil && (strings.HasPrefix(mediaType, "text/") || params["charset"] != "")
+	if textual && code != http.StatusNoContent && code != http.StatusNotModified {
+		// Only utf-8 output is transcoded.
+		if cs := params["charset"]; cs == "" || strings.EqualFold(cs, "utf-8") {
+			params["charset"] = strings.ToLower(rw.name)
+			h.Set("Content-Type", mime.FormatMediaType(mediaType, params))
+			h.Del("Content-Length")
+			rw.w = rw.enc.Writer(rw.ResponseWriter)
+		}
+	}
+	rw.ResponseWriter.WriteHeader(code)
+}
+
+func (rw *responseWriter) Write(p []byte) (int, error) {
+	if !rw.wroteHeader {
+		if rw.Header().Get("Content-Type") == "" {
+			rw.Header().Set("Content-Type", http.DetectContentType(p))
+		}
+		rw.WriteHeader(http.StatusOK)
+	}
+	switch {
+	case rw.rejected:
+		return 0, rw.bodyErr
+	case rw.w == nil:
+		return rw.ResponseWriter.Write(p)
+	}
+	return rw.w.Write(p)
+}
+
+// Flush sends the response written so far to the client, if the underlying
+// ResponseWriter supports it. A sequence split between two writes is held
+// until the next one.
+func (rw *responseWriter) Flush() {
+	if !rw.wroteHeader {
+		rw.WriteHeader(http.StatusOK)
+	}
+	if f, ok := rw.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Unwrap returns the underlying ResponseWriter, for http.ResponseController.
+func (rw *responseWriter) Unwrap() http.ResponseWriter {
+	return rw.ResponseWriter
+}
+
+// close flushes the encoder.
+func (rw *responseWriter) close() error {
+	if rw.w == nil {
+		return nil
+	}
+	return rw.w.Close()
+}
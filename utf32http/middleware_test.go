@@ -0,0 +1,168 @@
+package utf32http
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// utf32le returns the utf-32le encoding of str.
+func utf32le(str string) []byte {
+	var ret []byte
+	for _, r := range str {
+		ret = append(ret, byte(r), byte(r>>8), byte(r>>16), byte(r>>24))
+	}
+	return ret
+}
+
+func echo(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.Header().Set("X-Request-Type", r.Header.Get("Content-Type"))
+	_, _ = w.Write(body)
+}
+
+func TestMiddleware(t *testing.T) {
+	var tests = []struct {
+		contentType   string
+		acceptCharset string
+		body          []byte
+		status        int
+		responseType  string
+		requestType   string
+		response      []byte
+	}{
+		{
+			contentType:  "text/plain; charset=utf-32le",
+			body:         utf32le("héllo 世界"),
+			status:       http.StatusOK,
+			responseType: "text/plain; charset=utf-8",
+			requestType:  "text/plain; charset=utf-8",
+			response:     []byte("héllo 世界"),
+		},
+		{
+			contentType:   "text/plain",
+			acceptCharset: "iso-8859-1;q=0.9, utf-32le;q=0.8, utf-8;q=0.5",
+			body:          []byte("héllo"),
+			status:        http.StatusOK,
+			responseType:  "text/plain; charset=utf-32le",
+			requestType:   "text/plain",
+			response:      utf32le("héllo"),
+		},
+		{
+			contentType:   "text/plain; charset=UTF-32LE",
+			acceptCharset: "utf-32le",
+			body:          utf32le("日本"),
+			status:        http.StatusOK,
+			responseType:  "text/plain; charset=utf-32le",
+			requestType:   "text/plain; charset=utf-8",
+			response:      utf32le("日本"),
+		},
+		{
+			contentType: "text/plain; charset=klingon",
+			body:        []byte("x"),
+			status:      http.StatusUnsupportedMediaType,
+		},
+		{
+			contentType: "text/plain; charset=utf-32le",
+			body:        []byte{0, 0xd8, 0, 0},
+			status:      http.StatusBadRequest,
+		},
+	}
+	srv := httptest.NewServer(Middleware(http.HandlerFunc(echo)))
+	defer srv.Close()
+	for _, elem := range tests {
+		req, err := http.NewRequest(http.MethodPost, srv.URL, bytes.NewReader(elem.body))
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.Header.Set("Content-Type", elem.contentType)
+		if elem.acceptCharset != "" {
+			req.Header.Set("Accept-Charset", elem.acceptCharset)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		body, err := io.ReadAll(resp.Body)
+		_ = resp.Body.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if expect, got := elem.status, resp.StatusCode; expect != got {
+			t.Fatalf("Unexpected status for %q.\nExpect:\t%d\nGot:\t%d\n", elem.contentType, expect, got)
+		}
+		if elem.status != http.StatusOK {
+			continue
+		}
+		if expect, got := elem.responseType, resp.Header.Get("Content-Type"); expect != got {
+			t.Fatalf("Unexpected response type.\nExpect:\t%s\nGot:\t%s\n", expect, got)
+		}
+		if expect, got := elem.requestType, resp.Header.Get("X-Request-Type"); expect != got {
+			t.Fatalf("Unexpected request type.\nExpect:\t%s\nGot:\t%s\n", expect, got)
+		}
+		if expect, got := elem.response, body; !bytes.Equal(expect, got) {
+			t.Fatalf("Unexpected body.\nExpect:\t%x\nGot:\t%x\n", expect, got)
+		}
+	}
+}
+
+func TestMiddlewareBinary(t *testing.T) {
+	png := []byte("\x89PNG\r\n\x1a\n")
+	handler := Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write(png)
+	}))
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Accept-Charset", "utf-32le")
+	handler.ServeHTTP(rec, req)
+	if expect, got := "image/png", rec.Header().Get("Content-Type"); expect != got {
+		t.Fatalf("Unexpected content type.\nExpect:\t%s\nGot:\t%s\n", expect, got)
+	}
+	if !bytes.Equal(png, rec.Body.Bytes()) || strings.Contains(rec.Header().Get("Content-Type"), "charset") {
+		t.Fatalf("Unexpected transcoding of a binary body: %x\n", rec.Body.Bytes())
+	}
+}
+
+func TestMiddlewareStreaming(t *testing.T) {
+	// The handler gets the start of the body before the rest is sent.
+	pr, pw := io.Pipe()
+	started := make(chan struct{})
+	go func() {
+		_, _ = pw.Write(utf32le("ab"))
+		<-started
+		_, _ = pw.Write(utf32le("c"))
+		_ = pw.Close()
+	}()
+	handler := Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if expect, got := int64(-1), r.ContentLength; expect != got {
+			t.Errorf("Unexpected content length.\nExpect:\t%d\nGot:\t%d\n", expect, got)
+		}
+		buf := make([]byte, 2)
+		if _, err := io.ReadFull(r.Body, buf); err != nil {
+			t.Error(err)
+		}
+		close(started)
+		rest, err := io.ReadAll(r.Body)
+		if err != nil {
+			t.Error(err)
+		}
+		w.Header().Set("Content-Type", "text/plain")
+		_, _ = w.Write(append(buf, rest...))
+		w.(http.Flusher).Flush()
+	}))
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/", pr)
+	req.Header.Set("Content-Type", "text/plain; charset=utf-32le")
+	req.Header.Set("Accept-Charset", "utf-32le")
+	handler.ServeHTTP(rec, req)
+	if expect, got := utf32le("abc"), rec.Body.Bytes(); !bytes.Equal(expect, got) {
+		t.Fatalf("Unexpected body.\nExpect:\t%x\nGot:\t%x\n", expect, got)
+	}
+	if !rec.Flushed {
+		t.Fatal("Flush was not forwarded")
+	}
+}
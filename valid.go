@@ -0,0 +1,64 @@
+package utf32
+
+// Valid reports whether src holds only values ConvertUTF32toUTF8 accepts:
+// code points up to U+10FFFF that are not surrogates. It does not encode
+// src.
+func Valid(src []UTF32) bool {
+	return defaultConverter.ValidIndex(src) < 0
+}
+
+// ValidIndex returns the index of the first value of src that
+// ConvertUTF32toUTF8 rejects, or -1 if src is valid.
+func ValidIndex(src []UTF32) int {
+	return defaultConverter.ValidIndex(src)
+}
+
+// Repair returns src with each value that ConvertUTF32toUTF8 rejects
+// replaced by U+FFFD. See Converter.Repair.
+func Repair(src []UTF32) []UTF32 {
+	return defaultConverter.Repair(src)
+}
+
+// valid returns ch as Encode writes it, and whether it is written
+// unchanged.
+func (c *Converter) valid(ch UTF32) (UTF32, bool) {
+	if c.escape && ch >= escapeStart && ch <= escapeEnd {
+		return ch, true
+	}
+	repl, err := c.check(ch)
+	if err != nil {
+		if c.replace {
+			return c.replacement, false
+		}
+		return replacementChar, false
+	}
+	return repl, repl == ch
+}
+
+// ValidIndex returns the index of the first value of src that Encode would
+// reject or replace, following the options of c, or -1 if there is none.
+func (c *Converter) ValidIndex(src []UTF32) int {
+	for i, ch := range src {
+		if _, ok := c.valid(ch); !ok {
+			return i
+		}
+	}
+	return -1
+}
+
+// Repair returns src with each value that Encode would reject or replace
+// substituted: by the replacement of WithReplacement if set, else by
+// U+FFFD. src itself is returned if there is nothing to repair, else a
+// copy.
+func (c *Converter) Repair(src []UTF32) []UTF32 {
+	i := c.ValidIndex(src)
+	if i < 0 {
+		return src
+	}
+	ret := make([]UTF32, len(src))
+	copy(ret, src)
+	for ; i < len(ret); i++ {
+		ret[i], _ = c.valid(ret[i])
+	}
+	return ret
+}
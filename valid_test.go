@@ -0,0 +1,62 @@
+package utf32
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestValidIndex(t *testing.T) {
+	var tests = []struct {
+		src    []UTF32
+		expect int
+	}{
+		{src: nil, expect: -1},
+		{src: []UTF32{'a', 0x10ffff, 0xfffe}, expect: -1},
+		{src: []UTF32{'a', 0xd800}, expect: 1},
+		{src: []UTF32{0xdfff, 'a'}, expect: 0},
+		{src: []UTF32{'a', 'b', 0x110000}, expect: 2},
+		{src: []UTF32{'a', 0xffffffff}, expect: 1},
+	}
+	for _, elem := range tests {
+		if expect, got := elem.expect, ValidIndex(elem.src); expect != got {
+			t.Fatalf("Unexpected result for %x.\nExpect:\t%d\nGot:\t%d\n", elem.src, expect, got)
+		}
+		if expect, got := elem.expect < 0, Valid(elem.src); expect != got {
+			t.Fatalf("Unexpected validity for %x.\nExpect:\t%t\nGot:\t%t\n", elem.src, expect, got)
+		}
+		// Agree with the encoder.
+		if _, err := ConvertUTF32toUTF8(elem.src); (err == nil) != (elem.expect < 0) {
+			t.Fatalf("Validity of %x disagrees with ConvertUTF32toUTF8: %v", elem.src, err)
+		}
+	}
+}
+
+func TestRepair(t *testing.T) {
+	src := []UTF32{'a', 0xd800, 'b', 0x110000, 0xfdd0}
+	if expect, got := []UTF32{'a', 0xfffd, 'b', 0xfffd, 0xfdd0}, Repair(src); !reflect.DeepEqual(expect, got) {
+		t.Fatalf("Unexpected result.\nExpect:\t%x\nGot:\t%x\n", expect, got)
+	}
+	if expect, got := UTF32(0xd800), src[1]; expect != got {
+		t.Fatalf("Repair modified its input.\nExpect:\t%x\nGot:\t%x\n", expect, got)
+	}
+	if _, err := ConvertUTF32toUTF8(Repair(src)); err != nil {
+		t.Fatalf("Unexpected error encoding the repaired input: %s", err)
+	}
+
+	valid := []UTF32{'o', 'k'}
+	if got := Repair(valid); &got[0] != &valid[0] {
+		t.Fatal("Repair copied a valid input")
+	}
+
+	// Converters apply their own rules.
+	c := NewConverter(WithReplacement('?'), WithNoncharacters(NoncharactersReject))
+	if expect, got := 1, c.ValidIndex([]UTF32{'a', 0xfdd0}); expect != got {
+		t.Fatalf("Unexpected index.\nExpect:\t%d\nGot:\t%d\n", expect, got)
+	}
+	if expect, got := []UTF32{'a', '?', '?'}, c.Repair([]UTF32{'a', 0xd800, 0xfdd0}); !reflect.DeepEqual(expect, got) {
+		t.Fatalf("Unexpected result.\nExpect:\t%x\nGot:\t%x\n", expect, got)
+	}
+	if expect, got := -1, escapeConverter.ValidIndex(ConvertUTF8toUTF32Escape("a\xffb")); expect != got {
+		t.Fatalf("Unexpected index of escapes.\nExpect:\t%d\nGot:\t%d\n", expect, got)
+	}
+}
@@ -0,0 +1,85 @@
+package utf32
+
+import (
+	"errors"
+	"unsafe"
+)
+
+// wchar_t errors.
+var (
+	ErrEmbeddedNUL   = errors.New("string contains NUL")
+	ErrNotTerminated = errors.New("missing NUL terminator")
+)
+
+// WcharString converts the given utf-8 string to a NUL-terminated utf-32
+// buffer suitable for a wchar_t* parameter. On Linux and most Unix systems,
+// wchar_t is 32 bits wide and holds UTF-32, so the buffer can be passed to C
+// code as is. It fails with ErrEmbeddedNUL if s decodes to a NUL, including
+// from an overlong sequence such as "\xc0\x80", which C would take for the
+// end of the string.
+func WcharString(s string) ([]UTF32, error) {
+	ret, err := defaultConverter.decode(make([]UTF32, 0, len(s)+1), s, nil)
+	if err != nil {
+		return nil, err
+	}
+	for _, ch := range ret {
+		if ch == 0 {
+			return nil, ErrEmbeddedNUL
+		}
+	}
+	return append(ret, 0), nil
+}
+
+// GoStringFromWchar converts the NUL-terminated utf-32 buffer to a utf-8
+// string, stopping at the first NUL. It fails with ErrNotTerminated if buf
+// holds no NUL.
+func GoStringFromWchar(buf []UTF32) (string, error) {
+	n := wcharLen(buf)
+	if n < 0 {
+		return "", ErrNotTerminated
+	}
+	return ConvertUTF32toUTF8(buf[:n])
+}
+
+// WcharFromPointer returns a copy of the NUL-terminated wchar_t string at p,
+// without its terminator. At most limit values are read: if no NUL is found
+// among them, ErrNotTerminated is returned, so a missing terminator cannot
+// make it read past the end of the C buffer. A nil p is an empty string.
+func WcharFromPointer(p unsafe.Pointer, limit int) ([]UTF32, error) {
+	if p == nil {
+		return []UTF32{}, nil
+	}
+	for i := 0; i < limit; i++ {
+		if *(*UTF32)(unsafe.Add(p, i*4)) == 0 {
+			ret := make([]UTF32, i)
+			copy(ret, unsafe.Slice((*UTF32)(p), i))
+			return ret, nil
+		}
+	}
+	return nil, ErrNotTerminated
+}
+
+// ValidateWchar checks that buf holds a NUL terminator and that the values
+// before it are valid code points, which C code is not bound to ensure.
+func ValidateWchar(buf []UTF32) error {
+	n := wcharLen(buf)
+	if n < 0 {
+		return ErrNotTerminated
+	}
+	for _, ch := range buf[:n] {
+		if _, err := defaultConverter.check(ch); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// wcharLen returns the index of the first NUL of buf, or -1.
+func wcharLen(buf []UTF32) int {
+	for i, ch := range buf {
+		if ch == 0 {
+			return i
+		}
+	}
+	return -1
+}
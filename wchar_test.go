@@ -0,0 +1,69 @@
+package utf32
+
+import (
+	"testing"
+	"unsafe"
+)
+
+func TestWcharString(t *testing.T) {
+	buf, err := WcharString("héllo 𒔊")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expect, got := 8, len(buf); expect != got || buf[len(buf)-1] != 0 {
+		t.Fatalf("Unexpected buffer.\nExpect:\t%d values, NUL-terminated\nGot:\t%U\n", expect, buf)
+	}
+	if err := ValidateWchar(buf); err != nil {
+		t.Fatal(err)
+	}
+
+	// Simulate a C buffer, larger than the string it holds.
+	cbuf := append(append([]UTF32{}, buf...), 'x', 'y')
+	str, err := GoStringFromWchar(cbuf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expect, got := "héllo 𒔊", str; expect != got {
+		t.Fatalf("Unexpected result.\nExpect:\t%q\nGot:\t%q\n", expect, got)
+	}
+
+	for _, str := range []string{"a\x00b", "a\xc0\x80b"} {
+		if _, err := WcharString(str); err != ErrEmbeddedNUL {
+			t.Fatalf("Unexpected error for %q.\nExpect:\t%v\nGot:\t%v\n", str, ErrEmbeddedNUL, err)
+		}
+	}
+	if _, err := GoStringFromWchar([]UTF32{'a'}); err != ErrNotTerminated {
+		t.Fatalf("Unexpected error.\nExpect:\t%v\nGot:\t%v\n", ErrNotTerminated, err)
+	}
+	if err := ValidateWchar([]UTF32{'a', 0xd800, 0}); err != ErrInvalidSource {
+		t.Fatalf("Unexpected error.\nExpect:\t%v\nGot:\t%v\n", ErrInvalidSource, err)
+	}
+	if err := ValidateWchar([]UTF32{'a'}); err != ErrNotTerminated {
+		t.Fatalf("Unexpected error.\nExpect:\t%v\nGot:\t%v\n", ErrNotTerminated, err)
+	}
+}
+
+func TestWcharFromPointer(t *testing.T) {
+	cbuf := []UTF32{'a', 0xe9, 0x1250a, 0, 'z'}
+	p := unsafe.Pointer(&cbuf[0])
+
+	got, err := WcharFromPointer(p, len(cbuf))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expect := cbuf[:3]; !equal(expect, got) {
+		t.Fatalf("Unexpected result.\nExpect:\t%U\nGot:\t%U\n", expect, got)
+	}
+	// The result does not alias the C buffer.
+	cbuf[0] = 'b'
+	if got[0] != 'a' {
+		t.Fatal("Unexpected aliasing of the source buffer")
+	}
+
+	if _, err := WcharFromPointer(p, 3); err != ErrNotTerminated {
+		t.Fatalf("Unexpected error.\nExpect:\t%v\nGot:\t%v\n", ErrNotTerminated, err)
+	}
+	if got, err := WcharFromPointer(nil, 10); err != nil || len(got) != 0 {
+		t.Fatalf("Unexpected result for nil: %U, %v\n", got, err)
+	}
+}
@@ -0,0 +1,25 @@
+package utf32
+
+import "testing"
+
+func TestWidth(t *testing.T) {
+	var tests = []struct {
+		str   string
+		width int
+	}{
+		{str: "hello", width: 5},
+		{str: "日本語", width: 6},
+		{str: "ｈｉ", width: 4},
+		{str: "é", width: 1},
+		{str: "\U0001F468‍\U0001F469‍\U0001F467", width: 2},
+		{str: "❤️", width: 2},
+		{str: "\U0001F1EB\U0001F1F7", width: 2},
+		{str: "한가", width: 4},
+		{str: "a\tb", width: 2},
+	}
+	for _, elem := range tests {
+		if expect, got := elem.width, Width(mustConvert(t, elem.str)); expect != got {
+			t.Fatalf("Unexpected width for %q.\nExpect:\t%d\nGot:\t%d\n", elem.str, expect, got)
+		}
+	}
+}
@@ -0,0 +1,164 @@
+package utf32
+
+// Word break properties, as defined by UAX #29. Hebrew letters are taken as
+// ALetter, and ideographs and Hiragana as Other, so that each of them is a
+// word. They are derived from the tables of this package, so that words do
+// not change with the version of Go.
+type wordProperty int
+
+const (
+	wbOther wordProperty = iota
+	wbCR
+	wbLF
+	wbNewline
+	wbExtend // Extend, Format and ZWJ, ignored by WB4.
+	wbRegionalIndicator
+	wbKatakana
+	wbALetter
+	wbMidLetter
+	wbMidNum
+	wbMidNumLet
+	wbNumeric
+	wbExtendNumLet
+	wbWSegSpace
+)
+
+func lookupWordProperty(ch UTF32) wordProperty {
+	switch {
+	case ch == '\r':
+		return wbCR
+	case ch == '\n':
+		return wbLF
+	case ch == 0x0b, ch == 0x0c, ch == 0x85, ch == 0x2028, ch == 0x2029:
+		return wbNewline
+	case ch == 0x200b, ch > UniMaxLegalUTF32:
+		return wbOther
+	case ch == 0x200d, inRanges(ch, marksAndFormats):
+		return wbExtend
+	case ch >= 0x1f1e6 && ch <= 0x1f1ff:
+		return wbRegionalIndicator
+	case ch == 0x3031, ch == 0x3032, ch == 0x3033, ch == 0x3034, ch == 0x3035, ch == 0x309b, ch == 0x309c,
+		ch == 0x30a0, ch == 0x30fc, ch == 0xff70, inRanges(ch, katakana):
+		return wbKatakana
+	case ch == ':', ch == 0xb7, ch == 0x387, ch == 0x55f, ch == 0x5f4, ch == 0x2027, ch == 0xfe13, ch == 0xfe55, ch == 0xff1a:
+		return wbMidLetter
+	case ch == '.', ch == '\'', ch == 0x2018, ch == 0x2019, ch == 0x2024, ch == 0xfe52, ch == 0xff07, ch == 0xff0e:
+		return wbMidNumLet
+	case ch == ',', ch == ';', ch == 0x37e, ch == 0x589, ch == 0x60c, ch == 0x60d, ch == 0x66c, ch == 0x7f8,
+		ch == 0x2044, ch == 0xfe10, ch == 0xfe14, ch == 0xfe50, ch == 0xfe54, ch == 0xff0c, ch == 0xff1b:
+		return wbMidNum
+	case inRanges(ch, decimalDigits):
+		return wbNumeric
+	case ch == 0x202f, inRanges(ch, connectors):
+		return wbExtendNumLet
+	case inRanges(ch, hanHiragana):
+		return wbOther
+	case inRanges(ch, letters):
+		return wbALetter
+	case inRanges(ch, spaceSeparators):
+		return wbWSegSpace
+	}
+	return wbOther
+}
+
+// FirstWord returns the length, in code points, of the word at the start of
+// src, following the word boundary rules of UAX #29. Spaces and punctuation
+// between words form segments of their own. It returns 0 if src is empty.
+func FirstWord(src []UTF32) int {
+	if len(src) == 0 {
+		return 0
+	}
+	// skipExtend returns the index of the first code point from i that is
+	// not ignored by WB4.
+	skipExtend := func(i int) int {
+		for i < len(src) && lookupWordProperty(src[i]) == wbExtend {
+			i++
+		}
+		return i
+	}
+
+	prev := lookupWordProperty(src[0])
+	switch {
+	case prev == wbCR && len(src) > 1 && src[1] == '\n': // WB3
+		return 2
+	case prev == wbCR, prev == wbLF, prev == wbNewline: // WB3a
+		return 1
+	}
+	// The property before prev, for WB7 and WB11, and how many regional
+	// indicators the word holds, for WB15 and WB16.
+	prevPrev := wbOther
+	riCount := 0
+	if prev == wbRegionalIndicator {
+		riCount = 1
+	}
+	for i := skipExtend(1); i < len(src); {
+		cur := lookupWordProperty(src[i])
+		next := skipExtend(i + 1)
+		following := wbOther
+		if next < len(src) {
+			following = lookupWordProperty(src[next])
+		}
+		if wordBreak(prevPrev, prev, cur, following, riCount) &&
+			!(src[i-1] == 0x200d && isExtendedPictographic(src[i])) { // WB3c
+			return i
+		}
+		if cur == wbRegionalIndicator {
+			riCount++
+		}
+		prevPrev, prev, i = prev, cur, next
+	}
+	return len(src)
+}
+
+func wordBreak(prevPrev, prev, cur, following wordProperty, riCount int) bool {
+	switch {
+	case cur == wbCR, cur == wbLF, cur == wbNewline: // WB3b
+		return true
+	case prev == wbWSegSpace && cur == wbWSegSpace: // WB3d
+		return false
+	case prev == wbALetter && cur == wbALetter: // WB5
+		return false
+	case prev == wbALetter && (cur == wbMidLetter || cur == wbMidNumLet) && following == wbALetter: // WB6
+		return false
+	case prevPrev == wbALetter && (prev == wbMidLetter || prev == wbMidNumLet) && cur == wbALetter: // WB7
+		return false
+	case prev == wbNumeric && cur == wbNumeric, prev == wbALetter && cur == wbNumeric, prev == wbNumeric && cur == wbALetter: // WB8, WB9, WB10
+		return false
+	case prevPrev == wbNumeric && (prev == wbMidNum || prev == wbMidNumLet) && cur == wbNumeric: // WB11
+		return false
+	case prev == wbNumeric && (cur == wbMidNum || cur == wbMidNumLet) && following == wbNumeric: // WB12
+		return false
+	case prev == wbKatakana && cur == wbKatakana: // WB13
+		return false
+	case (prev == wbALetter || prev == wbNumeric || prev == wbKatakana || prev == wbExtendNumLet) && cur == wbExtendNumLet: // WB13a
+		return false
+	case prev == wbExtendNumLet && (cur == wbALetter || cur == wbNumeric || cur == wbKatakana): // WB13b
+		return false
+	case prev == wbRegionalIndicator && cur == wbRegionalIndicator: // WB15, WB16
+		return riCount%2 == 0
+	}
+	return true // WB999
+}
+
+// Words splits src into words and the segments between them, following
+// UAX #29. The returned slices share the backing array of src.
+func Words(src []UTF32) [][]UTF32 {
+	var ret [][]UTF32
+	for len(src) > 0 {
+		n := FirstWord(src)
+		ret = append(ret, src[:n:n])
+		src = src[n:]
+	}
+	return ret
+}
+
+// isWordLike reports whether the segment holds a letter or a number, as
+// opposed to spaces, punctuation or symbols.
+func isWordLike(segment []UTF32) bool {
+	for _, ch := range segment {
+		if inRanges(ch, letters) || inRanges(ch, numbers) {
+			return true
+		}
+	}
+	return false
+}
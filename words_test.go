@@ -0,0 +1,36 @@
+package utf32
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestWords(t *testing.T) {
+	var tests = []struct {
+		str    string
+		expect string
+	}{
+		{str: "", expect: ""},
+		{str: "Hello, world!", expect: "Hello|,| |world|!"},
+		{str: "can't stop", expect: "can't| |stop"},
+		{str: "3.14 or 1,000.5", expect: "3.14| |or| |1,000.5"},
+		{str: "e.g. foo_bar", expect: "e.g|.| |foo_bar"},
+		{str: "日本語のテキスト", expect: "日|本|語|の|テキスト"},
+		{str: "a  b\r\nc", expect: "a|  |b|\r\n|c"},
+		{str: "café au", expect: "café| |au"},
+		{str: "👨‍👩‍👧 ok", expect: "👨‍👩‍👧| |ok"},
+		{str: "🇫🇷🇯🇵", expect: "🇫🇷|🇯🇵"},
+		{str: "Кириллица и ελληνικά", expect: "Кириллица| |и| |ελληνικά"},
+		// A Kawi letter, assigned after UnicodeVersion.
+		{str: "ab\U00011f04cd", expect: "ab|\U00011f04|cd"},
+	}
+	for _, elem := range tests {
+		var got []string
+		for _, w := range Words(mustConvert(t, elem.str)) {
+			got = append(got, mustString(t, w))
+		}
+		if expect, got := elem.expect, strings.Join(got, "|"); expect != got {
+			t.Fatalf("Unexpected result for %q.\nExpect:\t%q\nGot:\t%q\n", elem.str, expect, got)
+		}
+	}
+}